@@ -3,40 +3,96 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"wails-lead-sheet/formats"
 	"wails-lead-sheet/parser"
+	"wails-lead-sheet/render"
+	"wails-lead-sheet/session"
+	"wails-lead-sheet/settings"
 )
 
 // App struct
 type App struct {
-	ctx context.Context
+	ctx       context.Context
+	settings  *settings.Store
+	documents *session.Manager
 }
 
-var lastDirectory string
-
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{}
+	return &App{documents: session.NewManager()}
 }
 
 // startup is called when the app starts. The context is saved
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	a.settings = loadSettings(ctx)
+	a.documents.SetTabWidth(a.settings.Get().TabWidth)
+
+	watcher := session.NewWatcher(a.documents, a.documentReloaded, a.documentReloadFailed)
+	go watcher.Run(ctx)
 }
 
-// ChooseFile lets the user choose an input file
-func (a *App) ChooseFile() string {
-	if lastDirectory == "" {
-		//lastDirectory = os.Getenv("HOME")
-		lastDirectory = "/Users/chris/hacks/music/wails-tab/song-tabs"
+// documentReloaded tells the frontend that an open song changed on disk
+func (a *App) documentReloaded(update session.Update) {
+	runtime.EventsEmit(a.ctx, "document:reloaded", update)
+}
+
+// documentReloadFailed tells the frontend that an open song changed on disk, but couldn't be read
+func (a *App) documentReloadFailed(id string, err error) {
+	runtime.LogPrintf(a.ctx, "Reload of document %s caught %v\n", id, err)
+	runtime.EventsEmit(a.ctx, "document:reload-failed", id, err.Error())
+}
+
+// loadSettings reads the user's settings, falling back to the defaults for
+// any which can't be found or read
+func loadSettings(ctx context.Context) *settings.Store {
+	path, err := settings.DefaultPath()
+	if err != nil {
+		runtime.LogPrintf(ctx, "Unable to find settings directory: %v\n", err)
+		path = filepath.Join(os.TempDir(), "wails-lead-sheet", "settings.json")
+	}
+
+	store, err := settings.Load(path)
+	if err != nil {
+		runtime.LogPrintf(ctx, "Unable to load settings from %s: %v\n", path, err)
+	}
+
+	return store
+}
+
+// startingDirectory returns the directory the file chooser should open in
+func (a *App) startingDirectory() string {
+	prefs := a.settings.Get()
+	for _, dir := range []string{prefs.LastDirectory, prefs.LibraryRoot} {
+		if dir == "" {
+			continue
+		}
+
+		info, err := os.Stat(dir)
+		if err == nil && info.IsDir() {
+			return dir
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
 	}
 
+	return home
+}
+
+// ChooseFile lets the user choose an input file
+func (a *App) ChooseFile() string {
 	file, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
-		DefaultDirectory:     lastDirectory,
+		DefaultDirectory:     a.startingDirectory(),
 		DefaultFilename:      "",
 		Title:                "Choose Song File",
 		CanCreateDirectories: false,
@@ -46,49 +102,230 @@ func (a *App) ChooseFile() string {
 		return fmt.Sprintf("Error: Unable to choose Song File: %v", err)
 	}
 
-	lastDirectory = filepath.Dir(file)
+	if file != "" {
+		err = a.SetLastDirectory(filepath.Dir(file))
+		if err != nil {
+			runtime.LogPrintf(a.ctx, "Unable to save last directory: %v\n", err)
+		}
+	}
 
 	return file
 }
 
-// RetrieveFileContents retrieves the contents from the given file path
-func (a *App) RetrieveFileContents(filePath string) (parser.ParsedContent, error) {
-	prsr := parser.ParsedContent{}
-	contents, err := os.ReadFile(filePath)
+// Open opens the song at the given file path, and returns the ID used to refer to it
+func (a *App) Open(filePath string) (string, error) {
+	id, err := a.documents.Open(filePath)
+	if err != nil {
+		runtime.LogPrintf(a.ctx, "Open of %s caught %v\n", filePath, err)
+		return "", err
+	}
+
+	return id, nil
+}
+
+// Close closes the song with the given ID
+func (a *App) Close(id string) error {
+	return a.documents.Close(id)
+}
+
+// Render returns every line of the song with the given ID
+func (a *App) Render(id string) (session.Update, error) {
+	document, err := a.documents.Get(id)
+	if err != nil {
+		return session.Update{}, err
+	}
+
+	return document.Render(), nil
+}
+
+// ImportReport says how the file of the song with the given ID was decoded
+func (a *App) ImportReport(id string) (formats.DecodeReport, error) {
+	document, err := a.documents.Get(id)
+	if err != nil {
+		return formats.DecodeReport{}, err
+	}
+
+	return document.ImportReport(), nil
+}
+
+// Diagnostics returns the warnings about lines of the song with the given ID
+// which may have been misread, so they can be pointed out
+func (a *App) Diagnostics(id string) ([]parser.Diagnostic, error) {
+	document, err := a.documents.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return document.Diagnostics(), nil
+}
+
+// Statistics sums up the song with the given ID as it is currently shown,
+// for planning a set
+func (a *App) Statistics(id string) (parser.Statistics, error) {
+	document, err := a.documents.Get(id)
+	if err != nil {
+		return parser.Statistics{}, err
+	}
+
+	return document.Content().Statistics(), nil
+}
+
+// StatisticsReport returns a report of the statistics of the song with the
+// given ID, as text or, if asJSON is set, as JSON
+func (a *App) StatisticsReport(id string, asJSON bool) (string, error) {
+	statistics, err := a.Statistics(id)
 	if err != nil {
-		runtime.LogPrintf(a.ctx, "Retrieve contents of %s contains caught %v\n", filePath, err)
-		return prsr, err
+		return "", err
 	}
 
-	err = prsr.ParseContent(string(contents))
+	var out strings.Builder
+	err = render.WriteStatistics(&out, statistics, asJSON)
 	if err != nil {
-		return prsr, err
+		runtime.LogPrintf(a.ctx, "StatisticsReport caught error %v\n", err)
+		return "", err
 	}
 
-	return prsr, nil
+	return out.String(), nil
 }
 
-// TransposeUpOneStep transposes the given content up one step
-func (a *App) TransposeUpOneStep(content parser.ParsedContent) parser.ParsedContent {
-	content.TransposeUpOneStep()
+// changeDocument applies the given change to the song with the given ID, and returns the lines which changed
+func (a *App) changeDocument(id string, change func(*session.Document) error) (session.Update, error) {
+	document, err := a.documents.Get(id)
+	if err != nil {
+		return session.Update{}, err
+	}
+
+	before := document.Content()
+	err = change(document)
 
-	return content
+	return document.UpdateSince(before), err
+}
+
+// TransposeUpOneStep transposes the song with the given ID up one step
+func (a *App) TransposeUpOneStep(id string) (session.Update, error) {
+	return a.changeDocument(id, func(d *session.Document) error {
+		return d.Transpose(1)
+	})
+}
+
+// TransposeDownOneStep transposes the song with the given ID down one step
+func (a *App) TransposeDownOneStep(id string) (session.Update, error) {
+	return a.changeDocument(id, func(d *session.Document) error {
+		return d.Transpose(-1)
+	})
+}
+
+// SwitchToNNS shows the song with the given ID as Nashville numbers in the
+// given key, writing minor chords as the settings say
+func (a *App) SwitchToNNS(id string, key string) (session.Update, error) {
+	return a.changeDocument(id, func(d *session.Document) error {
+		return d.SwitchToNNS(key, a.settings.Get().NNSMinorStyle == settings.NNSMinorDash)
+	})
 }
 
-// TransposeDownOneStep transposes the given content down one step
-func (a *App) TransposeDownOneStep(content parser.ParsedContent) parser.ParsedContent {
-	content.TransposeDownOneStep()
+// ClearNNS shows the song with the given ID as chords again
+func (a *App) ClearNNS(id string) (session.Update, error) {
+	return a.changeDocument(id, func(d *session.Document) error {
+		return d.ClearNNS()
+	})
+}
 
-	return content
+// RenameSection renames the section header on the given line of the song with the given ID
+func (a *App) RenameSection(id string, lineNumber int, name string) (session.Update, error) {
+	return a.changeDocument(id, func(d *session.Document) error {
+		return d.RenameSection(lineNumber, name)
+	})
 }
 
-// ExportToClipboard exports the given content to the clipboard
-func (a *App) ExportToClipboard(content parser.ParsedContent) string {
-	output := ""
-	for _, line := range content.Lines {
-		output += line.Text + "\n"
+// SetLineType sets the type of the given line of the song with the given ID,
+// for when it has been read wrongly. The type is one of "Section", "Chords",
+// "Lyrics" or "Text".
+func (a *App) SetLineType(id string, lineNumber int, lineType string) (session.Update, error) {
+	parsed, _ := parser.ParseLineType(lineType)
+	if parsed.String() != lineType {
+		return session.Update{}, fmt.Errorf("%#v is not a line type", lineType)
 	}
-	err := runtime.ClipboardSetText(a.ctx, output)
+
+	return a.changeDocument(id, func(d *session.Document) error {
+		return d.SetLineType(lineNumber, parsed)
+	})
+}
+
+// ClearLineType lets the type of the given line of the song with the given ID
+// be worked out from what it says again
+func (a *App) ClearLineType(id string, lineNumber int) (session.Update, error) {
+	return a.changeDocument(id, func(d *session.Document) error {
+		return d.ClearLineType(lineNumber)
+	})
+}
+
+// EditChord replaces a chord on the given line of the song with the given ID
+func (a *App) EditChord(id string, lineNumber int, partIndex int, chord string) (session.Update, error) {
+	return a.changeDocument(id, func(d *session.Document) error {
+		return d.EditChord(lineNumber, partIndex, chord)
+	})
+}
+
+// PreviewReharmonization returns the chords the given reharmonization would
+// change on the lines from first to last of the song with the given ID, or
+// on every line if last is -1, without changing them
+func (a *App) PreviewReharmonization(id string, technique string, first int, last int) ([]parser.ChordChange, error) {
+	document, err := a.documents.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if last < 0 {
+		last = len(document.Content().Lines) - 1
+	}
+
+	return document.PreviewReharmonization(technique, first, last)
+}
+
+// Reharmonize rewrites the chords on the lines from first to last of the
+// song with the given ID, or on every line if last is -1
+func (a *App) Reharmonize(id string, technique string, first int, last int) (session.Update, error) {
+	return a.changeDocument(id, func(d *session.Document) error {
+		if last < 0 {
+			last = len(d.Content().Lines) - 1
+		}
+
+		return d.Reharmonize(technique, first, last)
+	})
+}
+
+// Simplify shows the chords of the song with the given ID simplified to the
+// given level, which is empty to leave them as they are, without their bass
+// notes if dropBass is set, and as the shapes to play with a capo if capo is
+// set. The song itself isn't changed.
+func (a *App) Simplify(id string, level string, dropBass bool, capo bool) (session.Update, error) {
+	return a.changeDocument(id, func(d *session.Document) error {
+		return d.Simplify(parser.Simplification{Level: level, DropBass: dropBass, Capo: capo})
+	})
+}
+
+// Undo reverts the most recent change to the song with the given ID
+func (a *App) Undo(id string) (session.Update, error) {
+	return a.changeDocument(id, func(d *session.Document) error {
+		return d.Undo()
+	})
+}
+
+// Redo re-applies the most recently undone change to the song with the given ID
+func (a *App) Redo(id string) (session.Update, error) {
+	return a.changeDocument(id, func(d *session.Document) error {
+		return d.Redo()
+	})
+}
+
+// ExportToClipboard exports the song with the given ID to the clipboard
+func (a *App) ExportToClipboard(id string) string {
+	document, err := a.documents.Get(id)
+	if err != nil {
+		return err.Error()
+	}
+
+	err = runtime.ClipboardSetText(a.ctx, document.Text())
 	if err != nil {
 		runtime.LogPrintf(a.ctx, "ExportToClipboard caught error %v\n", err)
 		return err.Error()
@@ -96,3 +333,303 @@ func (a *App) ExportToClipboard(content parser.ParsedContent) string {
 
 	return ""
 }
+
+// songTitle makes a title for a song out of its file name
+func songTitle(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// shownKey returns the key a song is shown in, given the key it was written in
+func shownKey(key string, transposition int) string {
+	chord := parser.MakeChord(key)
+	if chord.Note == "" {
+		return ""
+	}
+
+	chord.Transpose(transposition)
+
+	return chord.String()
+}
+
+// exportTitle returns the title to put on an export of the song
+func exportTitle(document *session.Document) string {
+	title := document.Content().Metadata.Title
+	if title == "" {
+		return songTitle(document.Path())
+	}
+
+	return title
+}
+
+// exportKey returns the key to put on an export of the song. The key given
+// is the key the song was written in; if it is "" the song's own metadata
+// is used, if it has any.
+func exportKey(document *session.Document, key string) string {
+	if key == "" {
+		return document.Content().Metadata.Key
+	}
+
+	return shownKey(key, document.Transposition())
+}
+
+// chooseExportFile asks the user where to save an export of the given song
+func (a *App) chooseExportFile(document *session.Document, extension string, description string) (string, error) {
+	return runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		DefaultDirectory:     a.startingDirectory(),
+		DefaultFilename:      songTitle(document.Path()) + extension,
+		Title:                "Export " + description,
+		CanCreateDirectories: true,
+		Filters: []runtime.FileFilter{
+			{DisplayName: description, Pattern: "*" + extension},
+		},
+	})
+}
+
+// exportToFile asks the user where to save an export of the song with the
+// given ID, writes it there, and returns the file it was saved to
+func (a *App) exportToFile(id string, extension string, description string, write func(io.Writer, *session.Document) error) (string, error) {
+	document, err := a.documents.Get(id)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := a.chooseExportFile(document, extension, description)
+	if err != nil || file == "" {
+		return "", err
+	}
+
+	out, err := os.Create(file)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	err = write(out, document)
+	if err != nil {
+		runtime.LogPrintf(a.ctx, "Export of %s caught %v\n", file, err)
+		return "", err
+	}
+
+	return file, nil
+}
+
+// ExportPDF saves the song with the given ID as a PDF lead sheet, and returns
+// the file it was saved to. The key is the key the song was written in, or
+// "" if it isn't known.
+func (a *App) ExportPDF(id string, key string) (string, error) {
+	return a.exportToFile(id, ".pdf", "PDF lead sheet", func(out io.Writer, document *session.Document) error {
+		prefs := a.settings.Get()
+		return render.WritePDF(out, document.Content(), render.PDFOptions{
+			Title:    exportTitle(document),
+			Key:      exportKey(document, key),
+			PageSize: prefs.PageSize,
+			Columns:  prefs.ColumnCount,
+			FontSize: float64(prefs.FontSize),
+		})
+	})
+}
+
+// ExportHTML saves the song with the given ID as a self-contained HTML page,
+// and returns the file it was saved to
+func (a *App) ExportHTML(id string, key string) (string, error) {
+	return a.exportToFile(id, ".html", "HTML page", func(out io.Writer, document *session.Document) error {
+		return render.WriteHTML(out, document.Content(), render.HTMLOptions{
+			Title:      exportTitle(document),
+			Key:        exportKey(document, key),
+			Standalone: true,
+		})
+	})
+}
+
+// ExportGrid saves the chords of the song with the given ID as a bar grid,
+// as plain text or, if asked for, a self-contained HTML page, and returns
+// the file it was saved to
+func (a *App) ExportGrid(id string, key string, asHTML bool) (string, error) {
+	extension, description := ".txt", "chord grid"
+	if asHTML {
+		extension, description = ".html", "chord grid page"
+	}
+
+	return a.exportToFile(id, extension, description, func(out io.Writer, document *session.Document) error {
+		options := render.DefaultGridOptions()
+		options.Title = exportTitle(document)
+		options.Key = exportKey(document, key)
+		if asHTML {
+			options.Standalone = true
+			return render.WriteGridHTML(out, document.Content(), options)
+		}
+
+		return render.WriteGridText(out, document.Content(), options)
+	})
+}
+
+// ExportMusicXML saves the chord progression of the song with the given ID
+// as MusicXML for notation software, and returns the file it was saved to
+func (a *App) ExportMusicXML(id string, key string) (string, error) {
+	return a.exportToFile(id, ".musicxml", "MusicXML file", func(out io.Writer, document *session.Document) error {
+		return render.WriteMusicXML(out, document.Content(), render.MusicXMLOptions{
+			Title:  exportTitle(document),
+			Artist: document.Content().Metadata.Artist,
+			Key:    exportKey(document, key),
+		})
+	})
+}
+
+// ExportMIDI saves a backing track for the song with the given ID as a
+// MIDI file, and returns the file it was saved to. Each chord lasts the
+// given number of bars unless the chart says otherwise.
+func (a *App) ExportMIDI(id string, barsPerChord float64, click bool) (string, error) {
+	return a.exportToFile(id, ".mid", "MIDI file", func(out io.Writer, document *session.Document) error {
+		return render.WriteMIDI(out, document.Content(), render.MIDIOptions{
+			BarsPerChord: barsPerChord,
+			Click:        click,
+		})
+	})
+}
+
+// ExportWAV renders the song with the given ID to audio with the given
+// voice, saves it as a WAV file, and returns the file it was saved to
+func (a *App) ExportWAV(id string, voice string, barsPerChord float64) (string, error) {
+	return a.exportToFile(id, ".wav", "WAV audio", func(out io.Writer, document *session.Document) error {
+		options := render.DefaultWAVOptions()
+		options.Voice = voice
+		options.BarsPerChord = barsPerChord
+		return render.WriteWAV(out, document.Content(), options)
+	})
+}
+
+// ExportNashville saves the song with the given ID as a Nashville number
+// chart, and returns the file it was saved to
+func (a *App) ExportNashville(id string, key string) (string, error) {
+	return a.exportToFile(id, ".txt", "Nashville number chart", func(out io.Writer, document *session.Document) error {
+		options := render.DefaultNashvilleOptions()
+		options.Title = exportTitle(document)
+		options.Key = exportKey(document, key)
+		options.MinorDash = a.settings.Get().NNSMinorStyle == settings.NNSMinorDash
+		return render.WriteNashvilleChart(out, document.Content(), options)
+	})
+}
+
+// ExportOnSong saves the song with the given ID as an OnSong file, and
+// returns the file it was saved to
+func (a *App) ExportOnSong(id string) (string, error) {
+	return a.exportToFile(id, ".onsong", "OnSong file", func(out io.Writer, document *session.Document) error {
+		content := document.Content()
+		if content.Metadata.Title == "" {
+			content.Metadata.Title = songTitle(document.Path())
+		}
+
+		_, err := io.WriteString(out, formats.WriteOnSong(content))
+		return err
+	})
+}
+
+// ExportOpenSong saves the song with the given ID as an OpenSong file, and
+// returns the file it was saved to
+func (a *App) ExportOpenSong(id string) (string, error) {
+	return a.exportToFile(id, ".xml", "OpenSong file", func(out io.Writer, document *session.Document) error {
+		content := document.Content()
+		if content.Metadata.Title == "" {
+			content.Metadata.Title = songTitle(document.Path())
+		}
+
+		data, err := formats.WriteOpenSong(content)
+		if err != nil {
+			return err
+		}
+
+		_, err = out.Write(data)
+		return err
+	})
+}
+
+// ExportLyricsOnly saves the lyrics of the song with the given ID, without
+// its chords, and returns the file it was saved to
+func (a *App) ExportLyricsOnly(id string) (string, error) {
+	return a.exportToFile(id, ".txt", "Lyric sheet", func(out io.Writer, document *session.Document) error {
+		return render.WriteText(out, render.LyricsOnly(document.Content()))
+	})
+}
+
+// ExportChordsOnly saves the chords of the song with the given ID, without
+// its lyrics, and returns the file it was saved to
+func (a *App) ExportChordsOnly(id string) (string, error) {
+	return a.exportToFile(id, ".txt", "Chord sheet", func(out io.Writer, document *session.Document) error {
+		return render.WriteText(out, render.ChordsOnly(document.Content()))
+	})
+}
+
+// GetSettings returns the current user settings
+func (a *App) GetSettings() settings.Preferences {
+	return a.settings.Get()
+}
+
+// SetLibraryRoot sets the directory the song library lives in
+func (a *App) SetLibraryRoot(dir string) error {
+	return a.settings.Update(func(p *settings.Preferences) {
+		p.LibraryRoot = dir
+	})
+}
+
+// SetLastDirectory sets the directory the file chooser starts in
+func (a *App) SetLastDirectory(dir string) error {
+	return a.settings.Update(func(p *settings.Preferences) {
+		p.LastDirectory = dir
+	})
+}
+
+// SetSpellingPolicy sets whether transposed chords use sharps, flats, or follow the key
+func (a *App) SetSpellingPolicy(policy string) error {
+	return a.settings.Update(func(p *settings.Preferences) {
+		p.SpellingPolicy = policy
+	})
+}
+
+// SetNNSMinorStyle sets how minor chords are written in Nashville numbers
+func (a *App) SetNNSMinorStyle(style string) error {
+	return a.settings.Update(func(p *settings.Preferences) {
+		p.NNSMinorStyle = style
+	})
+}
+
+// SetPageSize sets the paper size used for printing
+func (a *App) SetPageSize(size string) error {
+	return a.settings.Update(func(p *settings.Preferences) {
+		p.PageSize = size
+	})
+}
+
+// SetColumnCount sets the number of columns used for printing
+func (a *App) SetColumnCount(count int) error {
+	return a.settings.Update(func(p *settings.Preferences) {
+		p.ColumnCount = count
+	})
+}
+
+// SetFontSize sets the font size used for printing
+func (a *App) SetFontSize(size int) error {
+	return a.settings.Update(func(p *settings.Preferences) {
+		p.FontSize = size
+	})
+}
+
+// SetInstrument sets the instrument chord information is shown for
+func (a *App) SetInstrument(instrument string) error {
+	return a.settings.Update(func(p *settings.Preferences) {
+		p.Instrument = instrument
+	})
+}
+
+// SetTabWidth sets how far apart tab stops are in songs opened from now on
+func (a *App) SetTabWidth(width int) error {
+	err := a.settings.Update(func(p *settings.Preferences) {
+		p.TabWidth = width
+	})
+	if err != nil {
+		return err
+	}
+
+	a.documents.SetTabWidth(width)
+
+	return nil
+}
@@ -0,0 +1,104 @@
+// Command leadsheet prints a lead sheet from the command line, the way the
+// app shows it.
+//
+//	leadsheet [-mode text|lyrics|chords] [-transpose steps] [-nns key] [-nns-minor dash|m] [-tab-width columns]
+//	          [-simplify power|triads|sevenths] [-drop-bass] [-capo]
+//	          [-stats text|json] file
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"wails-lead-sheet/formats"
+	"wails-lead-sheet/parser"
+	"wails-lead-sheet/render"
+	"wails-lead-sheet/session"
+	"wails-lead-sheet/settings"
+)
+
+var modes = map[string]func(parser.ParsedContent) parser.ParsedContent{
+	"text":   func(content parser.ParsedContent) parser.ParsedContent { return content },
+	"lyrics": render.LyricsOnly,
+	"chords": render.ChordsOnly,
+}
+
+func main() {
+	mode := flag.String("mode", "text", "what to print: text, lyrics or chords")
+	transpose := flag.Int("transpose", 0, "half steps to transpose the song by")
+	nns := flag.String("nns", "", "show Nashville numbers in the given key")
+	nnsMinor := flag.String("nns-minor", settings.Defaults().NNSMinorStyle, "write minor chords in Nashville numbers with a dash or m")
+	tabWidth := flag.Int("tab-width", formats.DefaultTabWidth, "columns between tab stops in the file")
+	simplify := flag.String("simplify", "", "simplify the chords to power chords, triads or sevenths")
+	dropBass := flag.Bool("drop-bass", false, "leave out the bass notes of slash chords")
+	capo := flag.Bool("capo", false, "show the chords as played with a capo, if one saves barre chords")
+	stats := flag.String("stats", "", "print the song's statistics instead, as text or json")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] file\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	simplification := parser.Simplification{Level: *simplify, DropBass: *dropBass, Capo: *capo}
+	err := run(flag.Arg(0), *mode, *transpose, *nns, *nnsMinor == settings.NNSMinorDash, *tabWidth, simplification, *stats)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(path string, mode string, transpose int, nns string, minorDash bool, tabWidth int, simplification parser.Simplification, stats string) error {
+	filter, ok := modes[mode]
+	if !ok {
+		return fmt.Errorf("unknown mode %q", mode)
+	}
+
+	if stats != "" && stats != "text" && stats != "json" {
+		return fmt.Errorf("unknown statistics format %q", stats)
+	}
+
+	document, err := session.OpenWithTabWidth(path, tabWidth)
+	if err != nil {
+		return err
+	}
+
+	report := document.ImportReport()
+	if len(report.Changes) > 0 {
+		fmt.Fprintln(os.Stderr, report)
+	}
+
+	if transpose != 0 {
+		err = document.Transpose(transpose)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = document.Simplify(simplification)
+	if err != nil {
+		return err
+	}
+
+	if stats != "" {
+		return render.WriteStatistics(os.Stdout, document.Content().Statistics(), stats == "json")
+	}
+
+	if document.Capo() > 0 {
+		fmt.Printf("Capo %d\n\n", document.Capo())
+	}
+
+	if nns != "" {
+		err = document.SwitchToNNS(nns, minorDash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return render.WriteText(os.Stdout, filter(document.Content()))
+}
@@ -0,0 +1,159 @@
+package formats
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+
+	"wails-lead-sheet/parser"
+)
+
+// DefaultTabWidth is the tab stop used when none is chosen
+const DefaultTabWidth = parser.DefaultTabWidth
+
+// DecodeReport says how a song file's text was read: the encoding it was
+// in, whether it started with a byte order mark, the line endings it used,
+// and how many tabs were expanded to spaces. Changes describes each thing
+// which was done to the text, and is empty if it was used as it was.
+type DecodeReport struct {
+	Encoding     string
+	BOM          bool
+	LineEndings  string
+	TabsExpanded int
+	TabWidth     int
+	Changes      []string
+}
+
+var byteOrderMarks = []struct {
+	mark     []byte
+	name     string
+	encoding encoding.Encoding
+}{
+	{[]byte{0xef, 0xbb, 0xbf}, "UTF-8", nil},
+	{[]byte{0xff, 0xfe}, "UTF-16LE", unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)},
+	{[]byte{0xfe, 0xff}, "UTF-16BE", unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)},
+}
+
+// detectEncoding works out which encoding the text is in, going by its
+// byte order mark if it has one. Without one, text is taken as UTF-16 if
+// every other byte is mostly zero, as it is for Western text, even though
+// plain ASCII in UTF-16 is valid UTF-8 too. Otherwise it is UTF-8 if it is
+// valid, and Windows-1252 if not, which older sites used, and which reads
+// Latin-1 the same way.
+func detectEncoding(data []byte) (string, int, encoding.Encoding) {
+	for _, bom := range byteOrderMarks {
+		if bytes.HasPrefix(data, bom.mark) {
+			return bom.name, len(bom.mark), bom.encoding
+		}
+	}
+
+	sample := data[:min(len(data), 4096)]
+	evenZeros, oddZeros := 0, 0
+	for index, b := range sample {
+		if b != 0 {
+			continue
+		}
+
+		if index%2 == 0 {
+			evenZeros += 1
+		} else {
+			oddZeros += 1
+		}
+	}
+
+	switch {
+	case len(sample)%2 == 0 && oddZeros > len(sample)/4 && evenZeros == 0:
+		return "UTF-16LE", 0, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	case len(sample)%2 == 0 && evenZeros > len(sample)/4 && oddZeros == 0:
+		return "UTF-16BE", 0, unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	}
+
+	if utf8.Valid(data) {
+		return "UTF-8", 0, nil
+	}
+
+	return "Windows-1252", 0, charmap.Windows1252
+}
+
+// lineEndings returns the kind of line endings the text uses: "LF", "CRLF",
+// "CR", "mixed", or "" if it is all on one line
+func lineEndings(text string) string {
+	crlf := strings.Count(text, "\r\n")
+	cr := strings.Count(text, "\r") - crlf
+	lf := strings.Count(text, "\n") - crlf
+
+	kinds := make([]string, 0)
+	for _, kind := range []struct {
+		name  string
+		count int
+	}{{"LF", lf}, {"CRLF", crlf}, {"CR", cr}} {
+		if kind.count > 0 {
+			kinds = append(kinds, kind.name)
+		}
+	}
+
+	switch len(kinds) {
+	case 0:
+		return ""
+	case 1:
+		return kinds[0]
+	}
+
+	return "mixed"
+}
+
+// Decode converts a song file's text to UTF-8, with LF line endings and
+// tabs expanded to the given width, and reports what it did
+func Decode(data []byte, tabWidth int) (string, DecodeReport, error) {
+	name, bom, enc := detectEncoding(data)
+	report := DecodeReport{Encoding: name, BOM: bom > 0, TabWidth: tabWidth, Changes: make([]string, 0)}
+
+	data = data[bom:]
+	if enc != nil {
+		decoded, err := enc.NewDecoder().Bytes(data)
+		if err != nil {
+			return "", report, fmt.Errorf("unable to read the file as %s: %w", name, err)
+		}
+
+		data = decoded
+		report.Changes = append(report.Changes, fmt.Sprintf("converted from %s", name))
+	}
+
+	if bom > 0 {
+		report.Changes = append(report.Changes, "removed the byte order mark")
+	}
+
+	text := string(data)
+	report.LineEndings = lineEndings(text)
+	if report.LineEndings != "" && report.LineEndings != "LF" {
+		text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+		report.Changes = append(report.Changes, fmt.Sprintf("changed %s line endings to LF", report.LineEndings))
+	}
+
+	if tabWidth > 0 {
+		report.TabsExpanded = strings.Count(text, "\t")
+		text = parser.ExpandTabs(text, tabWidth)
+	}
+	switch {
+	case report.TabsExpanded == 1:
+		report.Changes = append(report.Changes, fmt.Sprintf("expanded 1 tab to a stop every %d columns", tabWidth))
+	case report.TabsExpanded > 1:
+		report.Changes = append(report.Changes, fmt.Sprintf("expanded %d tabs to stops every %d columns", report.TabsExpanded, tabWidth))
+	}
+
+	return text, report, nil
+}
+
+// String describes what was done to the text, or says it was read as it was
+func (r DecodeReport) String() string {
+	if len(r.Changes) == 0 {
+		return fmt.Sprintf("Read as %s", r.Encoding)
+	}
+
+	return fmt.Sprintf("Read as %s: %s", r.Encoding, strings.Join(r.Changes, ", "))
+}
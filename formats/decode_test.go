@@ -0,0 +1,111 @@
+package formats
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+func utf16Bytes(text string, bigEndian bool) []byte {
+	res := make([]byte, 0)
+	for _, unit := range utf16.Encode([]rune(text)) {
+		if bigEndian {
+			res = append(res, byte(unit>>8), byte(unit))
+		} else {
+			res = append(res, byte(unit), byte(unit>>8))
+		}
+	}
+
+	return res
+}
+
+func TestDecodeEncodings(t *testing.T) {
+	const text = "[Verse]\nG      C\nCafé au lait\n"
+	for _, test := range []struct {
+		data     []byte
+		encoding string
+		bom      bool
+	}{
+		{[]byte(text), "UTF-8", false},
+		{append([]byte{0xef, 0xbb, 0xbf}, text...), "UTF-8", true},
+		{append([]byte{0xff, 0xfe}, utf16Bytes(text, false)...), "UTF-16LE", true},
+		{append([]byte{0xfe, 0xff}, utf16Bytes(text, true)...), "UTF-16BE", true},
+		{utf16Bytes(text, false), "UTF-16LE", false},
+		{utf16Bytes(text, true), "UTF-16BE", false},
+		{[]byte("[Verse]\nG      C\nCaf\xe9 au lait\n"), "Windows-1252", false},
+	} {
+		res, report, err := Decode(test.data, DefaultTabWidth)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res != text {
+			t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", text, res)
+		}
+
+		if report.Encoding != test.encoding || report.BOM != test.bom {
+			t.Errorf("Expected %s (BOM %v), got %#v", test.encoding, test.bom, report)
+		}
+	}
+}
+
+func TestDecodeASCIIInUTF16WithoutBOM(t *testing.T) {
+	const text = "[Verse]\nG C\n"
+	for _, test := range []struct {
+		data     []byte
+		encoding string
+	}{
+		{utf16Bytes(text, false), "UTF-16LE"},
+		{utf16Bytes(text, true), "UTF-16BE"},
+	} {
+		res, report, err := Decode(test.data, DefaultTabWidth)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res != text || report.Encoding != test.encoding {
+			t.Errorf("Expected %#v as %s, got %#v as %s", text, test.encoding, res, report.Encoding)
+		}
+	}
+}
+
+func TestDecodeLineEndingsAndTabs(t *testing.T) {
+	res, report, err := Decode([]byte("G\tC\tD\r\nI sing\tthe words\r\nEm\r"), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "G   C   D\nI sing  the words\nEm\n"
+	if res != expected {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, res)
+	}
+
+	expectedReport := DecodeReport{
+		Encoding:     "UTF-8",
+		LineEndings:  "mixed",
+		TabsExpanded: 3,
+		TabWidth:     4,
+		Changes:      []string{"changed mixed line endings to LF", "expanded 3 tabs to stops every 4 columns"},
+	}
+	if !reflect.DeepEqual(report, expectedReport) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expectedReport, report)
+	}
+}
+
+func TestDecodeOpenSongInUTF16(t *testing.T) {
+	data := utf16Bytes(`<?xml version="1.0" encoding="UTF-16"?><song><title>Test</title><lyrics>.G
+ Words</lyrics></song>`, false)
+	text, _, err := Decode(append([]byte{0xff, 0xfe}, data...), DefaultTabWidth)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := Import("song.xml", []byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if content.Metadata.Title != "Test" {
+		t.Errorf("Expected the title to be read, got %#v", content.Metadata)
+	}
+}
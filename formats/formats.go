@@ -0,0 +1,31 @@
+package formats
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"wails-lead-sheet/parser"
+)
+
+// Import parses a song in whichever format it is in, going by the file's
+// extension, or for XML, its contents. Anything else is read as plain
+// text (or Ultimate Guitar markup).
+func Import(path string, data []byte) (parser.ParsedContent, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".onsong":
+		return ReadOnSong(string(data))
+	case ".xml", ".opensong":
+		return ReadOpenSong(data)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("<?xml")) || bytes.HasPrefix(trimmed, []byte("<song")) {
+		return ReadOpenSong(data)
+	}
+
+	content := parser.ParsedContent{}
+	err := content.ParseContent(string(data))
+
+	return content, err
+}
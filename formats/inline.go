@@ -0,0 +1,84 @@
+package formats
+
+import (
+	"regexp"
+	"strings"
+
+	"wails-lead-sheet/parser"
+)
+
+var inlineChord = regexp.MustCompile(`\[([^\[\]]+)\]`)
+var bracketedHeading = regexp.MustCompile(`^\s*\[([^\]]+)\]\s*$`)
+
+func hasInlineChords(s string) bool {
+	return inlineChord.MatchString(s)
+}
+
+// sectionHeading returns the name of a section headed by its name alone in
+// brackets, as [Chorus], which would otherwise be read as an inline chord
+func sectionHeading(s string) (string, bool) {
+	match := bracketedHeading.FindStringSubmatch(s)
+	if match == nil || parser.MakeChord(strings.TrimSpace(match[1])).Note != "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(match[1]), true
+}
+
+// splitInlineChords returns the chord line, with the chords tagged, and the
+// lyric line for a line with inline chords, which are written in brackets
+// just before the syllable they go with. When chords are closer together
+// than they can be written, the lyrics are padded to make room.
+func splitInlineChords(s string) (string, string) {
+	lyric := ""
+	chords := ""
+	spans := make([]string, 0)
+	last := 0
+	for _, match := range inlineChord.FindAllStringSubmatchIndex(s, -1) {
+		lyric += s[last:match[0]]
+		last = match[1]
+		chord := strings.TrimSpace(s[match[2]:match[3]])
+		if chord == "" {
+			continue
+		}
+
+		column := parser.DisplayWidth(lyric)
+		if width := parser.DisplayWidth(chords); len(spans) > 0 && column <= width {
+			padding := width + 1 - column
+			lyric += strings.Repeat(" ", padding)
+			column += padding
+		}
+
+		chords = parser.PadToWidth(chords, column) + chord
+		spans = append(spans, chord)
+	}
+	lyric += s[last:]
+
+	return tagChords(chords, spans), strings.TrimRight(lyric, " ")
+}
+
+// tagChords wraps each of the chords, which appear in order in the text, in
+// chord tags
+func tagChords(text string, chords []string) string {
+	res := ""
+	for _, chord := range chords {
+		index := strings.Index(text, chord)
+		res += text[:index] + "[ch]" + chord + "[/ch]"
+		text = text[index+len(chord):]
+	}
+
+	return res + text
+}
+
+// mergeInlineChords writes the chords into the lyrics at their columns
+func mergeInlineChords(chords []parser.PlacedChord, lyric string) string {
+	text := strings.TrimRight(lyric, " ")
+	for index := len(chords) - 1; index >= 0; index-- {
+		chord := chords[index]
+		text = parser.PadToWidth(text, chord.Column)
+		width := parser.DisplayWidth(text)
+		text = parser.SliceColumns(text, 0, chord.Column) + "[" + chord.Chord + "]" + parser.SliceColumns(text, chord.Column, width)
+	}
+
+	return strings.TrimRight(text, " ")
+}
@@ -0,0 +1,177 @@
+package formats
+
+import (
+	"regexp"
+	"strings"
+
+	"wails-lead-sheet/parser"
+)
+
+var onSongMetadataLine = regexp.MustCompile(`^([A-Za-z][A-Za-z ]*?)\s*:\s*(.+)$`)
+var onSongSection = regexp.MustCompile(`^\s*([A-Za-z]\w*(?: \w+){0,2})\s*:\s*$`)
+var chordProDirective = regexp.MustCompile(`^\s*\{\s*([A-Za-z_]+)\s*(?::\s*(.*?))?\s*\}\s*$`)
+
+// setMetadata sets the metadata field with the given name, and reports
+// whether the name is one which is known
+func setMetadata(metadata *parser.Metadata, name string, value string) bool {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "title", "t":
+		metadata.Title = value
+	case "artist", "author", "subtitle", "st":
+		metadata.Artist = value
+	case "key":
+		metadata.Key = value
+	case "tempo", "bpm":
+		metadata.Tempo = value
+	case "time", "time signature", "timesig":
+		metadata.TimeSignature = value
+	case "capo":
+		metadata.Capo = value
+	case "flow":
+		metadata.Flow = strings.Fields(strings.ReplaceAll(value, ",", " "))
+	default:
+		return false
+	}
+
+	return true
+}
+
+// ReadOnSong parses a song in OnSong format: the title and artist, then
+// "Name: value" metadata, then the song with its chords inline and its
+// sections headed by a name ending in a colon, or in brackets
+func ReadOnSong(text string) (parser.ParsedContent, error) {
+	content := parser.ParsedContent{}
+	metadata := parser.Metadata{}
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	index := 0
+	for index < len(lines) && strings.TrimSpace(lines[index]) == "" {
+		index += 1
+	}
+
+	headerLines := 0
+	for ; index < len(lines); index++ {
+		s := strings.TrimSpace(lines[index])
+		if s == "" || hasInlineChords(s) || onSongSection.MatchString(s) {
+			break
+		}
+
+		match := chordProDirective.FindStringSubmatch(s)
+		if match != nil {
+			setMetadata(&metadata, match[1], match[2])
+			continue
+		}
+
+		match = onSongMetadataLine.FindStringSubmatch(s)
+		if match != nil && setMetadata(&metadata, match[1], match[2]) {
+			continue
+		}
+
+		if headerLines == 0 && metadata.Title == "" {
+			metadata.Title = s
+		} else if headerLines == 1 && metadata.Artist == "" {
+			metadata.Artist = s
+		} else {
+			break
+		}
+		headerLines += 1
+	}
+
+	converted := make([]string, 0, len(lines))
+	for ; index < len(lines); index++ {
+		s := strings.TrimRight(lines[index], " \t")
+
+		match := chordProDirective.FindStringSubmatch(s)
+		if match != nil {
+			switch strings.ToLower(match[1]) {
+			case "comment", "c", "ci", "comment_italic":
+				converted = append(converted, match[2])
+			case "start_of_chorus", "soc":
+				converted = append(converted, "[Chorus]")
+			default:
+				setMetadata(&metadata, match[1], match[2])
+			}
+			continue
+		}
+
+		match = onSongSection.FindStringSubmatch(s)
+		if match != nil {
+			converted = append(converted, "["+match[1]+"]")
+			continue
+		}
+
+		if name, found := sectionHeading(s); found {
+			converted = append(converted, "["+name+"]")
+			continue
+		}
+
+		if hasInlineChords(s) {
+			chords, lyric := splitInlineChords(s)
+			converted = append(converted, chords)
+			if strings.TrimSpace(lyric) != "" {
+				converted = append(converted, lyric)
+			}
+			continue
+		}
+
+		converted = append(converted, s)
+	}
+
+	err := content.ParseContent(strings.Join(converted, "\n"))
+	if err != nil {
+		return content, err
+	}
+
+	content.Metadata = metadata
+
+	return content, nil
+}
+
+// WriteOnSong writes the content in OnSong format, with chords inline
+func WriteOnSong(content parser.ParsedContent) string {
+	var out strings.Builder
+	metadata := content.Metadata
+	if metadata.Title != "" {
+		out.WriteString(metadata.Title + "\n")
+		if metadata.Artist != "" {
+			out.WriteString(metadata.Artist + "\n")
+		}
+	}
+
+	fields := [][2]string{
+		{"Key", metadata.Key},
+		{"Tempo", metadata.Tempo},
+		{"Time", metadata.TimeSignature},
+		{"Capo", metadata.Capo},
+		{"Flow", strings.Join(metadata.Flow, " ")},
+	}
+	for _, field := range fields {
+		if field[1] != "" {
+			out.WriteString(field[0] + ": " + field[1] + "\n")
+		}
+	}
+
+	if out.Len() > 0 {
+		out.WriteString("\n")
+	}
+
+	for index := 0; index < len(content.Lines); index++ {
+		line := content.Lines[index]
+		switch line.Type {
+		case parser.LineTypes.SECTION:
+			out.WriteString(strings.Trim(strings.TrimSpace(line.Text), "[]") + ":\n")
+		case parser.LineTypes.CHORDS:
+			lyric := ""
+			if index+1 < len(content.Lines) && content.Lines[index+1].Type == parser.LineTypes.LYRICS {
+				lyric = content.Lines[index+1].Text
+				index += 1
+			}
+			out.WriteString(mergeInlineChords(line.PlacedChords(), lyric) + "\n")
+		default:
+			out.WriteString(line.String() + "\n")
+		}
+	}
+
+	return out.String()
+}
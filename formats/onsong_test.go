@@ -0,0 +1,198 @@
+package formats
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"wails-lead-sheet/parser"
+)
+
+const onSongText = `Amazing Grace
+John Newton
+Key: G
+Tempo: 90
+Time: 3/4
+Capo: 2
+Flow: V1 C V1
+
+Verse 1:
+A[G]mazing [C]grace how [G]sweet the sound
+[D] [D7]
+
+Chorus:
+{comment: Slowly}
+[Em]I once was [Am]lost`
+
+func lineTexts(content parser.ParsedContent) []string {
+	res := make([]string, len(content.Lines))
+	for index, line := range content.Lines {
+		res[index] = line.String()
+	}
+
+	return res
+}
+
+func lineTypes(content parser.ParsedContent) []parser.LineType {
+	res := make([]parser.LineType, len(content.Lines))
+	for index, line := range content.Lines {
+		res[index] = line.Type
+	}
+
+	return res
+}
+
+func TestReadOnSong(t *testing.T) {
+	content, err := ReadOnSong(onSongText)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedMetadata := parser.Metadata{
+		Title:         "Amazing Grace",
+		Artist:        "John Newton",
+		Key:           "G",
+		Tempo:         "90",
+		TimeSignature: "3/4",
+		Capo:          "2",
+		Flow:          []string{"V1", "C", "V1"},
+	}
+	if !reflect.DeepEqual(content.Metadata, expectedMetadata) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expectedMetadata, content.Metadata)
+	}
+
+	expected := []string{
+		"[Verse 1]",
+		" G      C         G",
+		"Amazing grace how sweet the sound",
+		"D D7",
+		"",
+		"[Chorus]",
+		"Slowly",
+		"Em         Am",
+		"I once was lost",
+	}
+	if !reflect.DeepEqual(lineTexts(content), expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, lineTexts(content))
+	}
+
+	expectedTypes := []parser.LineType{
+		parser.LineTypes.SECTION,
+		parser.LineTypes.CHORDS,
+		parser.LineTypes.LYRICS,
+		parser.LineTypes.CHORDS,
+		parser.LineTypes.EMPTY,
+		parser.LineTypes.SECTION,
+		parser.LineTypes.LYRICS,
+		parser.LineTypes.CHORDS,
+		parser.LineTypes.LYRICS,
+	}
+	if !reflect.DeepEqual(lineTypes(content), expectedTypes) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expectedTypes, lineTypes(content))
+	}
+}
+
+func TestReadOnSongBracketedSections(t *testing.T) {
+	content, err := ReadOnSong("Song\n\n[Chorus]\n[G]Sing it [C]loud\n[Verse 2]\n[Am]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"[Chorus]", "G       C", "Sing it loud", "[Verse 2]", "Am"}
+	if !reflect.DeepEqual(lineTexts(content), expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, lineTexts(content))
+	}
+
+	expectedTypes := []parser.LineType{
+		parser.LineTypes.SECTION,
+		parser.LineTypes.CHORDS,
+		parser.LineTypes.LYRICS,
+		parser.LineTypes.SECTION,
+		parser.LineTypes.CHORDS,
+	}
+	if !reflect.DeepEqual(lineTypes(content), expectedTypes) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expectedTypes, lineTypes(content))
+	}
+
+	exported, err := WriteOpenSong(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(exported), ".Chorus") {
+		t.Errorf("Expected [Chorus] to be written as a section, got:\n%s", exported)
+	}
+}
+
+func TestSplitInlineChordsMakesRoom(t *testing.T) {
+	chords, lyric := splitInlineChords("[Am]A[G]men")
+	if chords != "[ch]Am[/ch] [ch]G[/ch]" {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", "[ch]Am[/ch] [ch]G[/ch]", chords)
+	}
+
+	if lyric != "A  men" {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", "A  men", lyric)
+	}
+}
+
+func TestInlineChordsOverWideLyrics(t *testing.T) {
+	chords, lyric := splitInlineChords("[C]日本[G]語の[Am]歌")
+	if chords != "[ch]C[/ch]   [ch]G[/ch]   [ch]Am[/ch]" {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", "[ch]C[/ch]   [ch]G[/ch]   [ch]Am[/ch]", chords)
+	}
+
+	if lyric != "日本語の歌" {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", "日本語の歌", lyric)
+	}
+
+	content, err := ReadOnSong("Verse:\n[C]Été, [F♯ø7]là-bas\n[C]日本[G]語の[Am]歌\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	written := WriteOnSong(content)
+	expected := "Verse:\n[C]Été, [F♯ø7]là-bas\n[C]日本[G]語の[Am]歌\n"
+	if written != expected {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, written)
+	}
+}
+
+func TestWriteOnSong(t *testing.T) {
+	content, err := ReadOnSong(onSongText)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content.Transpose(2)
+	content.Metadata = content.Metadata.Transposed(2)
+
+	expected := `Amazing Grace
+John Newton
+Key: A
+Tempo: 90
+Time: 3/4
+Capo: 2
+Flow: V1 C V1
+
+Verse 1:
+A[A]mazing [D]grace how [A]sweet the sound
+[E]  [E7]
+
+Chorus:
+Slowly
+[F#m]I once was [Bm]lost
+`
+	got := WriteOnSong(content)
+	if got != expected {
+		t.Errorf("Expected:\n'%s'\ngot:\n'%s'", expected, got)
+	}
+
+	again, err := ReadOnSong(got)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(lineTypes(again), lineTypes(content)) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", lineTypes(content), lineTypes(again))
+	}
+}
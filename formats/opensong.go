@@ -0,0 +1,139 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"wails-lead-sheet/parser"
+)
+
+type openSongCapo struct {
+	Print string `xml:"print,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// openSongLyrics is written with its line breaks as they are, rather than
+// as character references, so the file stays readable
+type openSongLyrics string
+
+func (l openSongLyrics) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	err := e.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	err = e.EncodeToken(xml.CharData(l))
+	if err != nil {
+		return err
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+type openSong struct {
+	XMLName      xml.Name       `xml:"song"`
+	Title        string         `xml:"title"`
+	Author       string         `xml:"author,omitempty"`
+	Key          string         `xml:"key,omitempty"`
+	Tempo        string         `xml:"tempo,omitempty"`
+	TimeSig      string         `xml:"time_sig,omitempty"`
+	Capo         *openSongCapo  `xml:"capo,omitempty"`
+	Presentation string         `xml:"presentation,omitempty"`
+	Lyrics       openSongLyrics `xml:"lyrics"`
+}
+
+// ReadOpenSong parses a song in OpenSong format
+func ReadOpenSong(data []byte) (parser.ParsedContent, error) {
+	content := parser.ParsedContent{}
+	song := openSong{}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// The text has been decoded to UTF-8 on import, whatever the file says
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	err := decoder.Decode(&song)
+	if err != nil {
+		return content, err
+	}
+
+	converted := make([]string, 0)
+	for _, s := range strings.Split(strings.ReplaceAll(string(song.Lyrics), "\r\n", "\n"), "\n") {
+		s = strings.TrimRight(s, " \t")
+		switch {
+		case strings.HasPrefix(s, "."):
+			chords := s[1:]
+			converted = append(converted, tagChords(chords, lo.Filter(strings.Fields(chords), func(field string, _ int) bool {
+				return !parser.IsRhythmMark(field)
+			})))
+		case strings.HasPrefix(s, ";"):
+			continue
+		case strings.HasPrefix(s, " "):
+			converted = append(converted, s[1:])
+		default:
+			converted = append(converted, s)
+		}
+	}
+
+	err = content.ParseContent(strings.Join(converted, "\n"))
+	if err != nil {
+		return content, err
+	}
+
+	content.Metadata = parser.Metadata{
+		Title:         strings.TrimSpace(song.Title),
+		Artist:        strings.TrimSpace(song.Author),
+		Key:           strings.TrimSpace(song.Key),
+		Tempo:         strings.TrimSpace(song.Tempo),
+		TimeSignature: strings.TrimSpace(song.TimeSig),
+		Flow:          strings.Fields(song.Presentation),
+	}
+	if song.Capo != nil {
+		content.Metadata.Capo = strings.TrimSpace(song.Capo.Value)
+	}
+
+	return content, nil
+}
+
+// WriteOpenSong writes the content in OpenSong format, as XML with the song
+// in its lyrics element, where chord lines start with '.' and lyrics with a
+// space
+func WriteOpenSong(content parser.ParsedContent) ([]byte, error) {
+	metadata := content.Metadata
+	song := openSong{
+		Title:        metadata.Title,
+		Author:       metadata.Artist,
+		Key:          metadata.Key,
+		Tempo:        metadata.Tempo,
+		TimeSig:      metadata.TimeSignature,
+		Presentation: strings.Join(metadata.Flow, " "),
+	}
+	if metadata.Capo != "" {
+		song.Capo = &openSongCapo{Print: "true", Value: metadata.Capo}
+	}
+
+	lyrics := make([]string, 0, len(content.Lines))
+	for _, line := range content.Lines {
+		switch line.Type {
+		case parser.LineTypes.SECTION:
+			lyrics = append(lyrics, strings.TrimSpace(line.Text))
+		case parser.LineTypes.CHORDS:
+			lyrics = append(lyrics, "."+line.String())
+		case parser.LineTypes.EMPTY:
+			lyrics = append(lyrics, "")
+		default:
+			lyrics = append(lyrics, " "+line.String())
+		}
+	}
+	song.Lyrics = openSongLyrics(strings.Join(lyrics, "\n"))
+
+	res, err := xml.MarshalIndent(song, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), append(res, '\n')...), nil
+}
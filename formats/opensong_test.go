@@ -0,0 +1,127 @@
+package formats
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+	"wails-lead-sheet/parser"
+)
+
+const openSongText = `<?xml version="1.0" encoding="UTF-8"?>
+<song>
+  <title>Amazing Grace</title>
+  <author>John Newton</author>
+  <key>G</key>
+  <tempo>90</tempo>
+  <time_sig>3/4</time_sig>
+  <capo print="false">2</capo>
+  <presentation>V1 C</presentation>
+  <lyrics>[V1]
+; sing it softly
+. G      C         G
+ Amazing grace how sweet the sound
+
+[C]
+.Em         Am
+ I once was lost</lyrics>
+</song>`
+
+func TestReadOpenSong(t *testing.T) {
+	content, err := ReadOpenSong([]byte(openSongText))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedMetadata := parser.Metadata{
+		Title:         "Amazing Grace",
+		Artist:        "John Newton",
+		Key:           "G",
+		Tempo:         "90",
+		TimeSignature: "3/4",
+		Capo:          "2",
+		Flow:          []string{"V1", "C"},
+	}
+	if !reflect.DeepEqual(content.Metadata, expectedMetadata) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expectedMetadata, content.Metadata)
+	}
+
+	expected := []string{
+		"[V1]",
+		" G      C         G",
+		"Amazing grace how sweet the sound",
+		"",
+		"[C]",
+		"Em         Am",
+		"I once was lost",
+	}
+	if !reflect.DeepEqual(lineTexts(content), expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, lineTexts(content))
+	}
+
+	if content.Lines[5].Type != parser.LineTypes.CHORDS {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", parser.LineTypes.CHORDS, content.Lines[5].Type)
+	}
+}
+
+func TestWriteOpenSong(t *testing.T) {
+	content, err := ReadOpenSong([]byte(openSongText))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := WriteOpenSong(content)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lyrics := "[V1]\n. G      C         G\n Amazing grace how sweet the sound\n\n[C]\n.Em         Am\n I once was lost</lyrics>"
+	if !strings.Contains(string(data), lyrics) {
+		t.Errorf("Expected lyrics:\n'%s'\ngot:\n'%s'", lyrics, data)
+	}
+
+	again, err := ReadOpenSong(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(lineTexts(again), lineTexts(content)) || !reflect.DeepEqual(again.Metadata, content.Metadata) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", content, again)
+	}
+}
+
+func TestImportChoosesFormat(t *testing.T) {
+	content, err := Import("song.txt", []byte(openSongText))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if content.Metadata.Title != "Amazing Grace" {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", "Amazing Grace", content.Metadata.Title)
+	}
+
+	content, err = Import("song.onsong", []byte(onSongText))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if content.Metadata.Key != "G" {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", "G", content.Metadata.Key)
+	}
+}
+
+func TestReadOpenSongRhythm(t *testing.T) {
+	content, err := ReadOpenSong([]byte("<song><title>T</title><lyrics>.| G . . . | C |</lyrics></song>"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	chords := lo.Map(content.Progression(), func(chord parser.ProgressionChord, _ int) [2]any {
+		return [2]any{chord.Text, chord.Beats}
+	})
+	expected := [][2]any{{"G", 4.0}, {"C", 4.0}}
+	if !reflect.DeepEqual(chords, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, chords)
+	}
+}
@@ -3,57 +3,54 @@ import { defineStore } from 'pinia'
 
 import {
   ChooseFile,
+  ClearLineType,
+  ClearNNS,
+  Close,
+  Diagnostics,
+  ExportChordsOnly,
+  ExportGrid,
+  ExportHTML,
+  ExportLyricsOnly,
+  ExportMIDI,
+  ExportMusicXML,
+  ExportNashville,
+  ExportOnSong,
+  ExportOpenSong,
+  ExportPDF,
   ExportToClipboard,
-  RetrieveFileContents,
+  ExportWAV,
+  ImportReport,
+  Open,
+  PreviewReharmonization,
+  Redo,
+  Reharmonize,
+  Render,
+  SetLineType,
+  Simplify,
+  StatisticsReport,
+  SwitchToNNS,
   TransposeDownOneStep,
   TransposeUpOneStep,
+  Undo,
 } from '../wailsjs/go/main/App'
-import { LogPrint } from '../wailsjs/runtime'
-import { parser } from '../wailsjs/go/models'
-
-type LetterRun = {
-  Type: string
-  Letters: string
-  OriginalLetters: string
-  Chord: string
-  TransposedLetters: string
-}
+import { EventsOn, LogPrint } from '../wailsjs/runtime'
+import { parser, session } from '../wailsjs/go/models'
 
 type Line = {
   LineNumber: number
   Text: string
-  Parts: LetterRun[]
+  Parts: any[]
   Type: string
+  Confidence: number
+  Overridden: boolean
 }
 
-const processTransposedLines = (
-  inputContent: parser.ParsedContent
-): { Lines: Line[] } => {
-  const content = inputContent as { Lines: Line[] }
-  const res: { Lines: Line[] } = { Lines: [] }
-  for (let lineIndex = 0; lineIndex < content.Lines.length; lineIndex += 1) {
-    const line = content.Lines[lineIndex]
-    if (line.Type === 'Chords') {
-      const newLine = {
-        LineNumber: line.LineNumber,
-        Text: '',
-        Parts: line.Parts,
-        Type: line.Type,
-      }
-
-      for (let partIndex = 0; partIndex < line.Parts.length; partIndex += 1) {
-        const part = line.Parts[partIndex]
-        if (part.Type === 'ChordRun' && part.TransposedLetters !== '') {
-          newLine.Text += part.TransposedLetters
-        } else {
-          newLine.Text += part.Letters
-        }
+type Content = { Lines: Line[] }
 
-        res.Lines[lineIndex] = newLine
-      }
-    } else {
-      res.Lines[lineIndex] = line
-    }
+const applyUpdate = (content: Content, update: session.Update): Content => {
+  const res: Content = { Lines: content.Lines.slice(0, update.LineCount) }
+  for (const line of update.Lines) {
+    res.Lines[line.LineNumber] = line as Line
   }
 
   return res
@@ -61,20 +58,38 @@ const processTransposedLines = (
 
 export const useContentStore = defineStore('counter', () => {
   const currentFileName = ref('')
-  const currentFileContent: Ref<parser.ParsedContent> = ref({ Lines: [] })
-  const processedFileContent: Ref<parser.ParsedContent> = ref({ Lines: [] })
+  const documentId = ref('')
+  const currentFileContent: Ref<Content> = ref({ Lines: [] })
+  const processedFileContent: Ref<Content> = ref({ Lines: [] })
   const currentKey: Ref<string> = ref('-')
   const errorMessage = ref('')
   const fileLoaded = ref(false)
   const loading = ref(false)
+  const showNNS = ref(false)
+  const canUndo = ref(false)
+  const canRedo = ref(false)
+  const diagnostics: Ref<parser.Diagnostic[]> = ref([])
+  const importChanges: Ref<string[]> = ref([])
+  const reharmonization = ref('')
+  const reharmonizationPreview: Ref<parser.ChordChange[]> = ref([])
+  const simplifyLevel = ref('')
+  const dropBass = ref(false)
+  const suggestCapo = ref(false)
+  const capo = ref(0)
+  const statisticsReport = ref('')
+
+  const lineWarnings = computed(() => (lineNumber: number) => {
+    return diagnostics.value
+      .filter((diagnostic) => diagnostic.LineNumber === lineNumber)
+      .map((diagnostic) => diagnostic.Message)
+      .join('\n')
+  })
 
   const lineClass = computed(() => (lineNumber: number) => {
     let res = `flex space-x-2`
 
-    switch (
-      currentFileContent.value != null &&
-      (currentFileContent.value as { Lines: Line[] }).Lines[lineNumber].Type
-    ) {
+    const line = processedFileContent.value.Lines[lineNumber]
+    switch (line != null && line.Type) {
       case 'Section':
         res += ` bg-cyan-100`
         break
@@ -86,11 +101,37 @@ export const useContentStore = defineStore('counter', () => {
         break
     }
 
+    if (
+      lineWarnings.value(lineNumber) !== '' ||
+      (line != null && line.Confidence < 0.5)
+    ) {
+      res += ` outline outline-2 outline-warning`
+    }
+
     return res
   })
 
+  const loadDiagnostics = async () => {
+    try {
+      diagnostics.value = await Diagnostics(documentId.value)
+    } catch (err: any) {
+      diagnostics.value = []
+      LogPrint(`error caught loading diagnostics: ${err}`)
+    }
+  }
+
   const keyChosen = computed(() => currentKey.value !== '-')
 
+  const trackState = (update: session.Update) => {
+    showNNS.value = update.NNSKey !== ''
+    canUndo.value = update.CanUndo
+    canRedo.value = update.CanRedo
+    simplifyLevel.value = update.Simplification.Level
+    dropBass.value = update.Simplification.DropBass
+    suggestCapo.value = update.Simplification.Capo
+    capo.value = update.Capo
+  }
+
   const retrieveFile = async () => {
     currentKey.value = '-'
     const fileOpened = await ChooseFile()
@@ -100,15 +141,21 @@ export const useContentStore = defineStore('counter', () => {
     } else {
       loading.value = true
       currentFileName.value = fileOpened
-      let content: parser.ParsedContent = parser.ParsedContent.createFrom({
-        Lines: [],
-      })
       currentFileContent.value = { Lines: [] }
       processedFileContent.value = { Lines: [] }
       try {
-        content = await RetrieveFileContents(currentFileName.value)
-        currentFileContent.value = content
-        processedFileContent.value = content
+        if (documentId.value !== '') {
+          await Close(documentId.value)
+          documentId.value = ''
+        }
+
+        documentId.value = await Open(currentFileName.value)
+        importChanges.value = (await ImportReport(documentId.value)).Changes
+        const update = await Render(documentId.value)
+        currentFileContent.value = applyUpdate({ Lines: [] }, update)
+        processedFileContent.value = currentFileContent.value
+        trackState(update)
+        await loadDiagnostics()
         fileLoaded.value = true
       } catch (err: any) {
         errorMessage.value = err.toString()
@@ -122,18 +169,138 @@ export const useContentStore = defineStore('counter', () => {
     }
   }
 
+  const applyChange = async (
+    change: (id: string) => Promise<session.Update>,
+    description: string
+  ) => {
+    try {
+      const update = await change(documentId.value)
+      processedFileContent.value = applyUpdate(
+        processedFileContent.value,
+        update
+      )
+      trackState(update)
+      await loadDiagnostics()
+      errorMessage.value = ''
+    } catch (err: any) {
+      errorMessage.value = err.toString()
+      LogPrint(
+        `error caught during ${description}: ${JSON.stringify(errorMessage.value, null, 2)}`
+      )
+    }
+  }
+
   const transposeUp = async () => {
-    const res = await TransposeUpOneStep(processedFileContent.value)
-    processedFileContent.value = processTransposedLines(res)
+    await applyChange(TransposeUpOneStep, 'transpose up')
   }
 
   const transposeDown = async () => {
-    const res = await TransposeDownOneStep(processedFileContent.value)
-    processedFileContent.value = processTransposedLines(res)
+    await applyChange(TransposeDownOneStep, 'transpose down')
+  }
+
+  const toggleNNS = async () => {
+    if (showNNS.value) {
+      await applyChange(ClearNNS, 'switch to chords')
+    } else {
+      await applyChange(
+        (id: string) => SwitchToNNS(id, currentKey.value),
+        'switch to NNS'
+      )
+    }
+  }
+
+  const setLineType = async (lineNumber: number, lineType: string) => {
+    if (lineType === '') {
+      await applyChange(
+        (id: string) => ClearLineType(id, lineNumber),
+        'clear line type'
+      )
+    } else {
+      await applyChange(
+        (id: string) => SetLineType(id, lineNumber, lineType),
+        'set line type'
+      )
+    }
+  }
+
+  const previewReharmonization = async () => {
+    try {
+      reharmonizationPreview.value = await PreviewReharmonization(
+        documentId.value,
+        reharmonization.value,
+        0,
+        -1
+      )
+      errorMessage.value = ''
+    } catch (err: any) {
+      reharmonizationPreview.value = []
+      errorMessage.value = err.toString()
+      LogPrint(`error caught previewing reharmonization: ${err}`)
+    }
+  }
+
+  const applyReharmonization = async () => {
+    await applyChange(
+      (id: string) => Reharmonize(id, reharmonization.value, 0, -1),
+      'reharmonize'
+    )
+    reharmonizationPreview.value = []
+  }
+
+  const cancelReharmonization = () => {
+    reharmonizationPreview.value = []
+  }
+
+  const simplify = async () => {
+    await applyChange(
+      (id: string) =>
+        Simplify(id, simplifyLevel.value, dropBass.value, suggestCapo.value),
+      'simplify'
+    )
+  }
+
+  const showStatistics = async () => {
+    try {
+      statisticsReport.value = await StatisticsReport(documentId.value, false)
+      errorMessage.value = ''
+    } catch (err: any) {
+      statisticsReport.value = ''
+      errorMessage.value = err.toString()
+      LogPrint(`error caught loading statistics: ${err}`)
+    }
+  }
+
+  const hideStatistics = () => {
+    statisticsReport.value = ''
+  }
+
+  const undo = async () => {
+    await applyChange(Undo, 'undo')
+  }
+
+  const redo = async () => {
+    await applyChange(Redo, 'redo')
   }
 
+  EventsOn('document:reloaded', async (update: session.Update) => {
+    if (update.ID !== documentId.value) {
+      return
+    }
+
+    processedFileContent.value = applyUpdate(processedFileContent.value, update)
+    trackState(update)
+    await loadDiagnostics()
+    errorMessage.value = ''
+  })
+
+  EventsOn('document:reload-failed', (id: string, message: string) => {
+    if (id === documentId.value) {
+      errorMessage.value = `Unable to reload the changed file: ${message}`
+    }
+  })
+
   const exportToClipboard = async () => {
-    const err = await ExportToClipboard(processedFileContent.value)
+    const err = await ExportToClipboard(documentId.value)
     if (err != '') {
       LogPrint(
         `error caught during export to clipboard: ${JSON.stringify(err, null, 2)}`
@@ -142,19 +309,114 @@ export const useContentStore = defineStore('counter', () => {
     }
   }
 
+  const exportToFile = async (
+    exporter: (id: string, key: string) => Promise<string>,
+    description: string
+  ) => {
+    try {
+      const key = keyChosen.value ? currentKey.value : ''
+      await exporter(documentId.value, key)
+      errorMessage.value = ''
+    } catch (err: any) {
+      errorMessage.value = err.toString()
+      LogPrint(
+        `error caught during ${description} export: ${JSON.stringify(errorMessage.value, null, 2)}`
+      )
+    }
+  }
+
+  const exportPDF = async () => {
+    await exportToFile(ExportPDF, 'PDF')
+  }
+
+  const exportHTML = async () => {
+    await exportToFile(ExportHTML, 'HTML')
+  }
+
+  const exportGrid = async () => {
+    await exportToFile((id, key) => ExportGrid(id, key, true), 'chord grid')
+  }
+
+  const exportMIDI = async () => {
+    await exportToFile((id) => ExportMIDI(id, 1, true), 'MIDI')
+  }
+
+  const exportWAV = async () => {
+    await exportToFile((id) => ExportWAV(id, 'pluck', 1), 'WAV')
+  }
+
+  const exportMusicXML = async () => {
+    await exportToFile(ExportMusicXML, 'MusicXML')
+  }
+
+  const exportNashville = async () => {
+    await exportToFile(ExportNashville, 'Nashville chart')
+  }
+
+  const exportOnSong = async () => {
+    await exportToFile((id) => ExportOnSong(id), 'OnSong')
+  }
+
+  const exportOpenSong = async () => {
+    await exportToFile((id) => ExportOpenSong(id), 'OpenSong')
+  }
+
+  const exportLyricsOnly = async () => {
+    await exportToFile((id) => ExportLyricsOnly(id), 'lyrics only')
+  }
+
+  const exportChordsOnly = async () => {
+    await exportToFile((id) => ExportChordsOnly(id), 'chords only')
+  }
+
   return {
+    applyReharmonization,
+    cancelReharmonization,
+    canRedo,
+    canUndo,
+    capo,
     currentFileName,
     currentFileContent,
     currentKey,
+    diagnostics,
+    documentId,
+    dropBass,
     errorMessage,
+    exportChordsOnly,
+    exportGrid,
+    exportHTML,
+    exportLyricsOnly,
+    exportMIDI,
+    exportMusicXML,
+    exportNashville,
+    exportOnSong,
+    exportOpenSong,
+    exportPDF,
     exportToClipboard,
+    exportWAV,
     fileLoaded,
+    hideStatistics,
+    importChanges,
     keyChosen,
     lineClass,
+    lineWarnings,
     loading,
+    previewReharmonization,
     processedFileContent,
+    redo,
+    reharmonization,
+    reharmonizationPreview,
     retrieveFile,
+    setLineType,
+    showNNS,
+    showStatistics,
+    simplify,
+    simplifyLevel,
+    statisticsReport,
+    suggestCapo,
+    toggleNNS,
     transposeDown,
     transposeUp,
+    undo,
   }
 })
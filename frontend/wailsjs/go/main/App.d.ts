@@ -1,13 +1,92 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {formats} from '../models';
 import {parser} from '../models';
+import {session} from '../models';
+import {settings} from '../models';
 
 export function ChooseFile():Promise<string>;
 
-export function ExportToClipboard(arg1:parser.ParsedContent):Promise<string>;
+export function ClearLineType(arg1:string,arg2:number):Promise<session.Update>;
 
-export function RetrieveFileContents(arg1:string):Promise<parser.ParsedContent>;
+export function ClearNNS(arg1:string):Promise<session.Update>;
 
-export function TransposeDownOneStep(arg1:parser.ParsedContent):Promise<parser.ParsedContent>;
+export function Close(arg1:string):Promise<void>;
 
-export function TransposeUpOneStep(arg1:parser.ParsedContent):Promise<parser.ParsedContent>;
+export function Diagnostics(arg1:string):Promise<Array<parser.Diagnostic>>;
+
+export function EditChord(arg1:string,arg2:number,arg3:number,arg4:string):Promise<session.Update>;
+
+export function ExportChordsOnly(arg1:string):Promise<string>;
+
+export function ExportGrid(arg1:string,arg2:string,arg3:boolean):Promise<string>;
+
+export function ExportHTML(arg1:string,arg2:string):Promise<string>;
+
+export function ExportLyricsOnly(arg1:string):Promise<string>;
+
+export function ExportMIDI(arg1:string,arg2:number,arg3:boolean):Promise<string>;
+
+export function ExportMusicXML(arg1:string,arg2:string):Promise<string>;
+
+export function ExportNashville(arg1:string,arg2:string):Promise<string>;
+
+export function ExportOnSong(arg1:string):Promise<string>;
+
+export function ExportOpenSong(arg1:string):Promise<string>;
+
+export function ExportPDF(arg1:string,arg2:string):Promise<string>;
+
+export function ExportToClipboard(arg1:string):Promise<string>;
+
+export function ExportWAV(arg1:string,arg2:string,arg3:number):Promise<string>;
+
+export function GetSettings():Promise<settings.Preferences>;
+
+export function ImportReport(arg1:string):Promise<formats.DecodeReport>;
+
+export function Open(arg1:string):Promise<string>;
+
+export function PreviewReharmonization(arg1:string,arg2:string,arg3:number,arg4:number):Promise<Array<parser.ChordChange>>;
+
+export function Redo(arg1:string):Promise<session.Update>;
+
+export function Reharmonize(arg1:string,arg2:string,arg3:number,arg4:number):Promise<session.Update>;
+
+export function RenameSection(arg1:string,arg2:number,arg3:string):Promise<session.Update>;
+
+export function Render(arg1:string):Promise<session.Update>;
+
+export function SetColumnCount(arg1:number):Promise<void>;
+
+export function SetFontSize(arg1:number):Promise<void>;
+
+export function SetInstrument(arg1:string):Promise<void>;
+
+export function SetLastDirectory(arg1:string):Promise<void>;
+
+export function SetLibraryRoot(arg1:string):Promise<void>;
+
+export function SetLineType(arg1:string,arg2:number,arg3:string):Promise<session.Update>;
+
+export function SetNNSMinorStyle(arg1:string):Promise<void>;
+
+export function SetPageSize(arg1:string):Promise<void>;
+
+export function SetSpellingPolicy(arg1:string):Promise<void>;
+
+export function SetTabWidth(arg1:number):Promise<void>;
+
+export function Simplify(arg1:string,arg2:string,arg3:boolean,arg4:boolean):Promise<session.Update>;
+
+export function Statistics(arg1:string):Promise<parser.Statistics>;
+
+export function StatisticsReport(arg1:string,arg2:boolean):Promise<string>;
+
+export function SwitchToNNS(arg1:string,arg2:string):Promise<session.Update>;
+
+export function TransposeDownOneStep(arg1:string):Promise<session.Update>;
+
+export function TransposeUpOneStep(arg1:string):Promise<session.Update>;
+
+export function Undo(arg1:string):Promise<session.Update>;
@@ -6,12 +6,160 @@ export function ChooseFile() {
   return window['go']['main']['App']['ChooseFile']();
 }
 
+export function ClearLineType(arg1, arg2) {
+  return window['go']['main']['App']['ClearLineType'](arg1, arg2);
+}
+
+export function ClearNNS(arg1) {
+  return window['go']['main']['App']['ClearNNS'](arg1);
+}
+
+export function Close(arg1) {
+  return window['go']['main']['App']['Close'](arg1);
+}
+
+export function Diagnostics(arg1) {
+  return window['go']['main']['App']['Diagnostics'](arg1);
+}
+
+export function EditChord(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['EditChord'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportChordsOnly(arg1) {
+  return window['go']['main']['App']['ExportChordsOnly'](arg1);
+}
+
+export function ExportGrid(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportGrid'](arg1, arg2, arg3);
+}
+
+export function ExportHTML(arg1, arg2) {
+  return window['go']['main']['App']['ExportHTML'](arg1, arg2);
+}
+
+export function ExportLyricsOnly(arg1) {
+  return window['go']['main']['App']['ExportLyricsOnly'](arg1);
+}
+
+export function ExportMIDI(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportMIDI'](arg1, arg2, arg3);
+}
+
+export function ExportMusicXML(arg1, arg2) {
+  return window['go']['main']['App']['ExportMusicXML'](arg1, arg2);
+}
+
+export function ExportNashville(arg1, arg2) {
+  return window['go']['main']['App']['ExportNashville'](arg1, arg2);
+}
+
+export function ExportOnSong(arg1) {
+  return window['go']['main']['App']['ExportOnSong'](arg1);
+}
+
+export function ExportOpenSong(arg1) {
+  return window['go']['main']['App']['ExportOpenSong'](arg1);
+}
+
+export function ExportPDF(arg1, arg2) {
+  return window['go']['main']['App']['ExportPDF'](arg1, arg2);
+}
+
 export function ExportToClipboard(arg1) {
   return window['go']['main']['App']['ExportToClipboard'](arg1);
 }
 
-export function RetrieveFileContents(arg1) {
-  return window['go']['main']['App']['RetrieveFileContents'](arg1);
+export function ExportWAV(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportWAV'](arg1, arg2, arg3);
+}
+
+export function GetSettings() {
+  return window['go']['main']['App']['GetSettings']();
+}
+
+export function ImportReport(arg1) {
+  return window['go']['main']['App']['ImportReport'](arg1);
+}
+
+export function Open(arg1) {
+  return window['go']['main']['App']['Open'](arg1);
+}
+
+export function PreviewReharmonization(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['PreviewReharmonization'](arg1, arg2, arg3, arg4);
+}
+
+export function Redo(arg1) {
+  return window['go']['main']['App']['Redo'](arg1);
+}
+
+export function Reharmonize(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['Reharmonize'](arg1, arg2, arg3, arg4);
+}
+
+export function RenameSection(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RenameSection'](arg1, arg2, arg3);
+}
+
+export function Render(arg1) {
+  return window['go']['main']['App']['Render'](arg1);
+}
+
+export function SetColumnCount(arg1) {
+  return window['go']['main']['App']['SetColumnCount'](arg1);
+}
+
+export function SetFontSize(arg1) {
+  return window['go']['main']['App']['SetFontSize'](arg1);
+}
+
+export function SetInstrument(arg1) {
+  return window['go']['main']['App']['SetInstrument'](arg1);
+}
+
+export function SetLastDirectory(arg1) {
+  return window['go']['main']['App']['SetLastDirectory'](arg1);
+}
+
+export function SetLibraryRoot(arg1) {
+  return window['go']['main']['App']['SetLibraryRoot'](arg1);
+}
+
+export function SetLineType(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetLineType'](arg1, arg2, arg3);
+}
+
+export function SetNNSMinorStyle(arg1) {
+  return window['go']['main']['App']['SetNNSMinorStyle'](arg1);
+}
+
+export function SetPageSize(arg1) {
+  return window['go']['main']['App']['SetPageSize'](arg1);
+}
+
+export function SetSpellingPolicy(arg1) {
+  return window['go']['main']['App']['SetSpellingPolicy'](arg1);
+}
+
+export function SetTabWidth(arg1) {
+  return window['go']['main']['App']['SetTabWidth'](arg1);
+}
+
+export function Simplify(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['Simplify'](arg1, arg2, arg3, arg4);
+}
+
+export function Statistics(arg1) {
+  return window['go']['main']['App']['Statistics'](arg1);
+}
+
+export function StatisticsReport(arg1, arg2) {
+  return window['go']['main']['App']['StatisticsReport'](arg1, arg2);
+}
+
+export function SwitchToNNS(arg1, arg2) {
+  return window['go']['main']['App']['SwitchToNNS'](arg1, arg2);
 }
 
 export function TransposeDownOneStep(arg1) {
@@ -21,3 +169,7 @@ export function TransposeDownOneStep(arg1) {
 export function TransposeUpOneStep(arg1) {
   return window['go']['main']['App']['TransposeUpOneStep'](arg1);
 }
+
+export function Undo(arg1) {
+  return window['go']['main']['App']['Undo'](arg1);
+}
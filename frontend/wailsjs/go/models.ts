@@ -1,15 +1,270 @@
+export namespace formats {
+	
+	export class DecodeReport {
+	    Encoding: string;
+	    BOM: boolean;
+	    LineEndings: string;
+	    TabsExpanded: number;
+	    TabWidth: number;
+	    Changes: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DecodeReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Encoding = source["Encoding"];
+	        this.BOM = source["BOM"];
+	        this.LineEndings = source["LineEndings"];
+	        this.TabsExpanded = source["TabsExpanded"];
+	        this.TabWidth = source["TabWidth"];
+	        this.Changes = source["Changes"];
+	    }
+	}
+
+}
+
 export namespace parser {
 	
-	export class ParsedContent {
+	export class ChordChange {
+	    LineNumber: number;
+	    Part: number;
+	    From: string;
+	    To: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ChordChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.LineNumber = source["LineNumber"];
+	        this.Part = source["Part"];
+	        this.From = source["From"];
+	        this.To = source["To"];
+	    }
+	}
+	export class ChordCount {
+	    Chord: string;
+	    Count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ChordCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Chord = source["Chord"];
+	        this.Count = source["Count"];
+	    }
+	}
+	export class Diagnostic {
+	    LineNumber: number;
+	    Column: number;
+	    EndColumn: number;
+	    Kind: string;
+	    Message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Diagnostic(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.LineNumber = source["LineNumber"];
+	        this.Column = source["Column"];
+	        this.EndColumn = source["EndColumn"];
+	        this.Kind = source["Kind"];
+	        this.Message = source["Message"];
+	    }
+	}
+	export class ProgressionCount {
+	    Numbers: string;
+	    Chords: string;
+	    Count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProgressionCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Numbers = source["Numbers"];
+	        this.Chords = source["Chords"];
+	        this.Count = source["Count"];
+	    }
+	}
+	export class Simplification {
+	    Level: string;
+	    DropBass: boolean;
+	    Capo: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Simplification(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Level = source["Level"];
+	        this.DropBass = source["DropBass"];
+	        this.Capo = source["Capo"];
+	    }
+	}
+	export class Statistics {
+	    Key: string;
+	    Keys: string[];
+	    DistinctChords: string[];
+	    ChordFrequency: ChordCount[];
+	    Progressions: ProgressionCount[];
+	    BarreChords: string[];
+	    Sections: number;
+	    Lines: number;
+	    ChordLines: number;
+	    LyricLines: number;
+	    Bars: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Statistics(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.Key = source["Key"];
+	        this.Keys = source["Keys"];
+	        this.DistinctChords = source["DistinctChords"];
+	        this.ChordFrequency = this.convertValues(source["ChordFrequency"], ChordCount);
+	        this.Progressions = this.convertValues(source["Progressions"], ProgressionCount);
+	        this.BarreChords = source["BarreChords"];
+	        this.Sections = source["Sections"];
+	        this.Lines = source["Lines"];
+	        this.ChordLines = source["ChordLines"];
+	        this.LyricLines = source["LyricLines"];
+	        this.Bars = source["Bars"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace session {
+	
+	export class RenderedLine {
+	    LineNumber: number;
+	    Type: any;
+	    Text: string;
+	    Parts: any[];
+	    Confidence: number;
+	    Overridden: boolean;
 	
+	    static createFrom(source: any = {}) {
+	        return new RenderedLine(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.LineNumber = source["LineNumber"];
+	        this.Type = source["Type"];
+	        this.Text = source["Text"];
+	        this.Parts = source["Parts"];
+	        this.Confidence = source["Confidence"];
+	        this.Overridden = source["Overridden"];
+	    }
+	}
+	export class Update {
+	    ID: string;
+	    LineCount: number;
+	    Lines: RenderedLine[];
+	    Transposition: number;
+	    NNSKey: string;
+	    Simplification: parser.Simplification;
+	    Capo: number;
+	    CanUndo: boolean;
+	    CanRedo: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new ParsedContent(source);
+	        return new Update(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ID = source["ID"];
+	        this.LineCount = source["LineCount"];
+	        this.Lines = this.convertValues(source["Lines"], RenderedLine);
+	        this.Transposition = source["Transposition"];
+	        this.NNSKey = source["NNSKey"];
+	        this.Simplification = this.convertValues(source["Simplification"], parser.Simplification);
+	        this.Capo = source["Capo"];
+	        this.CanUndo = source["CanUndo"];
+	        this.CanRedo = source["CanRedo"];
+	    }
 	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace settings {
+	
+	export class Preferences {
+	    libraryRoot: string;
+	    lastDirectory: string;
+	    spellingPolicy: string;
+	    nnsMinorStyle: string;
+	    pageSize: string;
+	    columnCount: number;
+	    fontSize: number;
+	    instrument: string;
+	    tabWidth: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Preferences(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.libraryRoot = source["libraryRoot"];
+	        this.lastDirectory = source["lastDirectory"];
+	        this.spellingPolicy = source["spellingPolicy"];
+	        this.nnsMinorStyle = source["nnsMinorStyle"];
+	        this.pageSize = source["pageSize"];
+	        this.columnCount = source["columnCount"];
+	        this.fontSize = source["fontSize"];
+	        this.instrument = source["instrument"];
+	        this.tabWidth = source["tabWidth"];
 	    }
 	}
 
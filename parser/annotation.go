@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"strings"
+)
+
+var noteDelimiters = map[string]string{"(": ")", "[": "]", "*": "*"}
+
+const arrows = "→←↑↓↔⇒⇐⇔➔➜➝"
+
+func isArrow(text string) bool {
+	return text != "" && strings.Trim(text, arrows) == ""
+}
+
+func makeAnnotationRun(text string) LetterRun {
+	return LetterRun{Letters: text, OriginalLetters: text, Type: LetterRunTypes.ANNOTATIONRUN}
+}
+
+// appendSeparator adds a separator to the runs, joining it to the one
+// before if that is a separator too
+func appendSeparator(res []LetterRun, text string) []LetterRun {
+	if len(res) > 0 && res[len(res)-1].Type == LetterRunTypes.SEPARATORRUN {
+		res[len(res)-1].Letters += text
+		res[len(res)-1].OriginalLetters = res[len(res)-1].Letters
+		return res
+	}
+
+	return append(res, LetterRun{Letters: text, OriginalLetters: text, Type: LetterRunTypes.SEPARATORRUN})
+}
+
+// isNote reports whether the runs between a pair of delimiters are a note,
+// because they hold something other than chords
+func isNote(cells []LetterRun) bool {
+	for _, cell := range cells {
+		if cell.Type == LetterRunTypes.ANNOTATIONRUN {
+			return true
+		}
+
+		if cell.Type != LetterRunTypes.SEPARATORRUN && !isChord(cell.Letters) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// markAnnotations turns the notes, repeat marks and arrows on a line into
+// annotation runs, which aren't read as chords or transposed
+func markAnnotations(parts []LetterRun) []LetterRun {
+	// Separators are split into single characters, since a note can start
+	// or end in the middle of one
+	cells := make([]LetterRun, 0, len(parts))
+	for _, part := range parts {
+		if part.Type != LetterRunTypes.SEPARATORRUN {
+			cells = append(cells, part)
+			continue
+		}
+
+		graphemes(part.Letters, func(cluster string, _ int) {
+			cells = append(cells, LetterRun{Letters: cluster, Type: LetterRunTypes.SEPARATORRUN})
+		})
+	}
+
+	res := make([]LetterRun, 0, len(parts))
+	for index := 0; index < len(cells); index++ {
+		cell := cells[index]
+		if cell.Type == LetterRunTypes.SEPARATORRUN {
+			closing, opens := noteDelimiters[cell.Letters]
+			end := -1
+			depth := 0
+			for next := index + 1; opens && next < len(cells) && end < 0; next++ {
+				if cells[next].Type != LetterRunTypes.SEPARATORRUN {
+					continue
+				}
+
+				switch {
+				case cells[next].Letters == closing && depth == 0:
+					end = next
+				case cells[next].Letters == closing:
+					depth -= 1
+				case cells[next].Letters == cell.Letters:
+					depth += 1
+				}
+			}
+
+			if end < 0 || !isNote(cells[index+1:end]) {
+				res = appendSeparator(res, cell.Letters)
+				continue
+			}
+
+			text := ""
+			for _, inside := range cells[index : end+1] {
+				text += inside.Letters
+			}
+			res = append(res, makeAnnotationRun(text))
+			index = end
+			continue
+		}
+
+		if cell.Type != LetterRunTypes.ANNOTATIONRUN && isArrow(cell.Letters) {
+			cell = makeAnnotationRun(cell.Letters)
+		}
+
+		res = append(res, cell)
+	}
+
+	return res
+}
+
+// noteText returns the text of an annotation without its delimiters, or ""
+// if it is a repeat mark or an arrow rather than a note
+func noteText(annotation string) string {
+	text := strings.TrimSpace(annotation)
+	if closing, found := noteDelimiters[text[:1]]; found && strings.HasSuffix(text, closing) && len(text) > 1 {
+		text = strings.TrimSpace(text[1 : len(text)-1])
+	}
+
+	if repeatMark.MatchString(text) || isArrow(text) {
+		return ""
+	}
+
+	return text
+}
+
+// attachComments sets the text of each note on a chord line as the comment
+// of the chord before it, or the one after it if it comes first
+func attachComments(parts []LetterRun) {
+	for index, part := range parts {
+		if part.Type != LetterRunTypes.ANNOTATIONRUN {
+			continue
+		}
+
+		text := noteText(part.Letters)
+		if text == "" {
+			continue
+		}
+
+		chord := -1
+		for before := index - 1; before >= 0 && chord < 0; before-- {
+			if parts[before].Type == LetterRunTypes.CHORDRUN {
+				chord = before
+			}
+		}
+
+		for after := index + 1; after < len(parts) && chord < 0; after++ {
+			if parts[after].Type == LetterRunTypes.CHORDRUN {
+				chord = after
+			}
+		}
+
+		if chord >= 0 {
+			parts[chord].Comment = strings.TrimSpace(parts[chord].Comment + " " + text)
+		}
+	}
+}
+
+// chordLineRuns splits a line's text into runs as a chord line, with its
+// rhythm marks and notes picked out
+func chordLineRuns(text string) []LetterRun {
+	return markAnnotations(markRhythm(makeLetterRuns(text)))
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/samber/lo"
+)
+
+func annotationTexts(line Line) []string {
+	annotations := lo.Filter(line.Parts, func(part LetterRun, _ int) bool {
+		return part.Type == LetterRunTypes.ANNOTATIONRUN
+	})
+
+	return lo.Map(annotations, func(part LetterRun, _ int) string {
+		return part.Letters
+	})
+}
+
+func TestAnnotationsOnChordLine(t *testing.T) {
+	content := ParsedContent{}
+	err := content.ParseContent("G  C  (hold)  D  N.C.  *riff*  x2  →  [let ring]\nSomething to sing\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []LineType{LineTypes.CHORDS, LineTypes.LYRICS}
+	if !reflect.DeepEqual(lineTypes(content), expected) {
+		t.Fatalf("Expected:\n'%#v'\ngot:\n'%#v'", expected, lineTypes(content))
+	}
+
+	line := content.Lines[0]
+	expectedNotes := []string{"(hold)", "*riff*", "x2", "→", "[let ring]"}
+	if !reflect.DeepEqual(annotationTexts(line), expectedNotes) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expectedNotes, annotationTexts(line))
+	}
+
+	if line.Repeat != 2 {
+		t.Errorf("Expected the line to be played twice, got %d", line.Repeat)
+	}
+
+	chords := lo.Map(line.PlacedChords(), func(chord PlacedChord, _ int) string { return chord.Chord })
+	if !reflect.DeepEqual(chords, []string{"G", "C", "D", "N.C."}) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", []string{"G", "C", "D", "N.C."}, chords)
+	}
+
+	if line.Parts[line.PlacedChords()[1].Part].Comment != "hold" {
+		t.Errorf("Expected (hold) to be kept with C")
+	}
+
+	if len(content.Diagnostics) != 0 {
+		t.Errorf("Expected no warnings about the notes, got %#v", content.Diagnostics)
+	}
+
+	content.Transpose(3)
+	expectedText := "A# D# (hold)  F  N.C.  *riff*  x2  →  [let ring]"
+	if content.Lines[0].String() != expectedText {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expectedText, content.Lines[0].String())
+	}
+}
+
+func TestAnnotationsOnlyWhereThereAreWords(t *testing.T) {
+	content := ParsedContent{}
+	err := content.ParseContent("E   A   (G)   B7   (x2)\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := content.Lines[0]
+	if !reflect.DeepEqual(annotationTexts(line), []string{"(x2)"}) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", []string{"(x2)"}, annotationTexts(line))
+	}
+
+	if len(line.PlacedChords()) != 4 || line.Repeat != 2 {
+		t.Errorf("Expected (G) to stay a chord and the line to be played twice, got %#v", line.Parts)
+	}
+}
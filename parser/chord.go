@@ -1,6 +1,10 @@
 package parser
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/samber/lo"
+)
 
 type Chord struct {
 	Note               string
@@ -9,6 +13,25 @@ type Chord struct {
 	Flavor             string
 	OriginalString     string
 	OriginalAccidental AccidentalType
+	Symbols            bool
+}
+
+// chordSymbols are written in chords as shorthand: ♯ and ♭ for sharp and
+// flat, Δ for a major seventh, ø for half diminished and ° for diminished
+const chordSymbols = "♯♭Δø°"
+
+var accidentalSymbols = strings.NewReplacer("♯", "#", "♭", "b")
+var suffixSymbols = strings.NewReplacer("Δ7", "maj7", "Δ", "maj", "ø7", "m7b5", "ø", "m7b5", "°7", "dim7", "°", "dim",
+	"♯", "#", "♭", "b")
+
+// chordSuffix returns the suffix a chord suffix written with symbols stands for
+func chordSuffix(flavor string) string {
+	res := suffixSymbols.Replace(strings.ReplaceAll(flavor, "δ", "Δ"))
+	if strings.HasSuffix(res, "maj") {
+		res += "7"
+	}
+
+	return res
 }
 
 func nextUp(note string) string {
@@ -51,11 +74,11 @@ func (c *Chord) String() string {
 	res := c.Note
 
 	if c.Accidental == AccidentalTypes.SHARP {
-		res += "#"
+		res += lo.Ternary(c.Symbols, "♯", "#")
 	}
 
 	if c.Accidental == AccidentalTypes.FLAT {
-		res += "b"
+		res += lo.Ternary(c.Symbols, "♭", "b")
 	}
 
 	res += c.Flavor
@@ -68,6 +91,12 @@ func (c *Chord) String() string {
 }
 
 func MakeChord(original string) Chord {
+	if strings.ContainsAny(original, "♯♭") {
+		res := MakeChord(accidentalSymbols.Replace(original))
+		res.useSymbols()
+		return res
+	}
+
 	res := Chord{}
 	if len(original) == 0 {
 		return res
@@ -134,9 +163,10 @@ func MakeChord(original string) Chord {
 		return res
 	}
 
-	_, found := knownChordSuffixes[copyOfOriginal[start:]]
+	flavor := strings.ReplaceAll(copyOfOriginal[start:], "δ", "Δ")
+	_, found := knownChordSuffixes[chordSuffix(flavor)]
 	if found {
-		res.Flavor = copyOfOriginal[start:]
+		res.Flavor = flavor
 	} else {
 		return Chord{}
 	}
@@ -152,6 +182,20 @@ func MakeChord(original string) Chord {
 	return res
 }
 
+// useSymbols has the chord, and its bass note, written with ♯ and ♭
+func (c *Chord) useSymbols() {
+	if c.Note == "" {
+		return
+	}
+
+	c.Symbols = true
+	if c.BassNote != nil {
+		c.BassNote.useSymbols()
+	}
+
+	c.OriginalString = c.String()
+}
+
 func (c *Chord) Reset() {
 	c.Note = c.OriginalString
 	c.Accidental = c.OriginalAccidental
@@ -208,3 +252,13 @@ func (c *Chord) StepDown() {
 		c.Note = nextDown(c.Note)
 	}
 }
+
+func (c *Chord) Transpose(steps int) {
+	for range steps % 12 {
+		c.StepUp()
+	}
+
+	for range -steps % 12 {
+		c.StepDown()
+	}
+}
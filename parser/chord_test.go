@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -215,3 +216,76 @@ func TestChordReset(t *testing.T) {
 		t.Errorf("Expected %s chord, got %s", "A", c.String())
 	}
 }
+
+func TestChordTranspose(t *testing.T) {
+	for _, check := range []struct {
+		source   string
+		steps    int
+		expected string
+	}{
+		{"A", 2, "B"},
+		{"Am7/G", 3, "Cm7/A#"},
+		{"C", -1, "B"},
+		{"Eb", -14, "Db"},
+		{"G", 12, "G"},
+		{"G", 0, "G"},
+	} {
+		c := MakeChord(check.source)
+		c.Transpose(check.steps)
+		if c.String() != check.expected {
+			t.Errorf("Expected %s transposed by %d to be %s, got %s", check.source, check.steps, check.expected, c.String())
+		}
+	}
+}
+
+func TestChordTheory(t *testing.T) {
+	chord := MakeChord("Bbm7/Ab")
+	if chord.Root() != 10 || chord.Bass() != 8 {
+		t.Errorf("Expected root 10 and bass 8, got %d and %d", chord.Root(), chord.Bass())
+	}
+
+	if chord.Quality().Kind != "minor-seventh" {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", "minor-seventh", chord.Quality().Kind)
+	}
+
+	expected := []int{10, 1, 5, 8}
+	if !reflect.DeepEqual(chord.Tones(), expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, chord.Tones())
+	}
+
+	if MakeChord("N.C.").Root() != -1 || len(MakeChord("N.C.").Tones()) != 0 {
+		t.Errorf("Expected N.C. to have no root or tones")
+	}
+
+	for suffix := range knownChordSuffixes {
+		if MakeChord("C"+suffix).Quality().Kind == "other" {
+			t.Errorf("Expected a known kind for %#v", suffix)
+		}
+	}
+}
+
+func TestNashvilleNumber(t *testing.T) {
+	cases := []struct {
+		chord     string
+		key       string
+		minorDash bool
+		expected  string
+	}{
+		{"G", "D", false, "4"},
+		{"F#m", "D", false, "3m"},
+		{"F#m7", "D", true, "3-7"},
+		{"F", "D", true, "b3"},
+		{"Bb", "C", false, "b7"},
+		{"A#", "Eb", false, "5"},
+		{"Cmaj7", "C", true, "1maj7"},
+		{"D/F#", "D", false, "1/3"},
+		{"C#", "C", false, "#1"},
+	}
+
+	for _, c := range cases {
+		got := MakeChord(c.chord).NashvilleNumber(MakeChord(c.key), c.minorDash)
+		if got != c.expected {
+			t.Errorf("For %s in %s expected:\n'%#v'\ngot:\n'%#v'", c.chord, c.key, c.expected, got)
+		}
+	}
+}
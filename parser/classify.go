@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"strings"
+)
+
+// chordThreshold is the score at which a line is read as chords
+const chordThreshold = 0.6
+
+// doubtful is how far either side of the threshold a line's score has to
+// be before its neighbours are not taken into account
+const doubtful = 0.25
+
+const stopwordList = "a about after all am an and are as at be been but by can do for from had has have he her him " +
+	"his how i if in into is it its just like me my no not now of on or our out she so than that the their them " +
+	"then there they this to too up us was we were what when where who why will with would you your"
+
+var stopwords map[string]bool
+
+func init() {
+	stopwords = make(map[string]bool)
+	for _, word := range strings.Split(stopwordList, " ") {
+		stopwords[word] = true
+	}
+}
+
+// chordScore returns how much a line's runs look like chords rather than
+// lyrics, from 0 to 1
+func chordScore(parts []LetterRun) float64 {
+	chords, ambiguous, words := 0.0, 0.0, 0.0
+	gaps, wideGaps := 0, 0
+	first := true
+	for index, part := range parts {
+		if part.Type == LetterRunTypes.SEPARATORRUN {
+			if index > 0 && index < len(parts)-1 {
+				gaps += 1
+				if strings.Contains(part.Letters, "  ") || strings.ContainsAny(part.Letters, "|\t") {
+					wideGaps += 1
+				}
+			}
+			continue
+		}
+
+		if part.Type == LetterRunTypes.ANNOTATIONRUN {
+			continue
+		}
+
+		lower := strings.ToLower(part.Letters)
+		capitalized := part.Letters != lower
+		switch {
+		case isChord(part.Letters) && stopwords[lower] && (!capitalized || first):
+			ambiguous += 1
+		case isChord(part.Letters):
+			chords += 1
+		case stopwords[lower]:
+			words += 2
+		default:
+			words += 1
+		}
+		first = false
+	}
+
+	if words == 0 && (chords > 0 || ambiguous == 1) {
+		return 1
+	}
+
+	total := chords + ambiguous + words
+	if total == 0 {
+		return 0
+	}
+
+	score := (chords + ambiguous/2) / total
+	if gaps > 0 {
+		score += 0.15 * (2*float64(wideGaps)/float64(gaps) - 1)
+	}
+
+	return min(1, max(0, score))
+}
+
+// classifyLines decides which of the lines which are neither sections nor
+// empty are chords, and which are lyrics
+func (p *ParsedContent) classifyLines(lines []int) {
+	scores := make(map[int]float64)
+	parts := make(map[int][]LetterRun)
+	for _, index := range lines {
+		parts[index] = chordLineRuns(p.Lines[index].Text)
+		scores[index] = chordScore(parts[index])
+	}
+
+	for _, index := range lines {
+		score := scores[index]
+		if score > chordThreshold-doubtful && score < chordThreshold+doubtful {
+			next, found := scores[index+1]
+			if found && next < chordThreshold-doubtful {
+				score += 0.15
+			}
+
+			if index > 0 && p.Lines[index-1].Type == LineTypes.CHORDS {
+				score -= 0.15
+			}
+		}
+
+		p.Lines[index].Confidence = score
+		if score >= chordThreshold {
+			p.Lines[index].Type = LineTypes.CHORDS
+			p.Lines[index].Parts = parts[index]
+			attachComments(p.Lines[index].Parts)
+		} else {
+			p.Lines[index].Type = LineTypes.LYRICS
+			p.Lines[index].Parts = makeLetterRuns("")
+			p.Lines[index].Confidence = 1 - score
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassifyAnnotatedChordLine(t *testing.T) {
+	content := ParsedContent{}
+	err := content.ParseContent("A  E  F#m  (let ring)\nSomething to sing\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []LineType{LineTypes.CHORDS, LineTypes.LYRICS}
+	if !reflect.DeepEqual(lineTypes(content), expected) {
+		t.Fatalf("Expected:\n'%#v'\ngot:\n'%#v'", expected, lineTypes(content))
+	}
+
+	chords := content.Lines[0].PlacedChords()
+	if len(chords) != 3 {
+		t.Fatalf("Expected 3 chords, got %#v", chords)
+	}
+
+	comment := content.Lines[0].Parts[chords[2].Part].Comment
+	if comment != "let ring" {
+		t.Errorf("Expected (let ring) to be kept with F#m, got %#v", comment)
+	}
+
+	if content.Progression()[2].Comment != "let ring" {
+		t.Errorf("Expected the note to follow F#m into the progression")
+	}
+
+	if len(content.Diagnostics) != 0 {
+		t.Errorf("Expected no warnings about the note, got %#v", content.Diagnostics)
+	}
+
+	content.Transpose(2)
+	if content.Lines[0].String() != "B  F# G#m  (let ring)" {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", "B  F# G#m  (let ring)", content.Lines[0].String())
+	}
+}
+
+func TestClassifyWordsWhichAreChords(t *testing.T) {
+	for _, text := range []string{"Be a bad add", "A Bad Dad", "Be a babe", "a cab"} {
+		content := ParsedContent{}
+		err := content.ParseContent(text + "\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if content.Lines[0].Type != LineTypes.LYRICS {
+			t.Errorf("Expected %q to be lyrics", text)
+		}
+	}
+
+	for _, text := range []string{"A", "Am", "a - B|C / / /| D E", "E   A   (G)   B7", "N.C."} {
+		content := ParsedContent{}
+		err := content.ParseContent(text + "\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if content.Lines[0].Type != LineTypes.CHORDS {
+			t.Errorf("Expected %q to be chords", text)
+		}
+	}
+}
+
+func TestClassifyBySpacingAndNeighbours(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected []LineType
+	}{
+		{"C G day\n", []LineType{LineTypes.LYRICS}},
+		{"C    G    day\n", []LineType{LineTypes.CHORDS}},
+		{"C  day  way\n", []LineType{LineTypes.LYRICS}},
+		{"C  day  way\nThe words go here\n", []LineType{LineTypes.CHORDS, LineTypes.LYRICS}},
+		{"D  G\nC  day  way\nThe words go here\n", []LineType{LineTypes.CHORDS, LineTypes.LYRICS, LineTypes.LYRICS}},
+	}
+
+	for _, c := range cases {
+		content := ParsedContent{}
+		err := content.ParseContent(c.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(lineTypes(content), c.expected) {
+			t.Errorf("Expected %q to be:\n'%#v'\ngot:\n'%#v'", c.text, c.expected, lineTypes(content))
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// Kinds of diagnostic
+const (
+	DiagnosticUnknownChord = "unknown-chord"
+	DiagnosticEmptyChord   = "empty-chord"
+	DiagnosticMixedLine    = "mixed-line"
+	DiagnosticNoLyrics     = "no-lyrics"
+)
+
+// Diagnostic is a warning about a line which may have been misread. The
+// columns span the part of the line it is about, from Column up to EndColumn.
+type Diagnostic struct {
+	LineNumber int
+	Column     int
+	EndColumn  int
+	Kind       string
+	Message    string
+}
+
+// wordSpan is a word in a line, with the columns it takes up
+type wordSpan struct {
+	run    LetterRun
+	index  int
+	column int
+	end    int
+}
+
+// wordSpans returns the runs of a line which aren't separators, along with
+// their columns
+func wordSpans(runs []LetterRun) []wordSpan {
+	res := make([]wordSpan, 0)
+	column := 0
+	for index, run := range runs {
+		width := DisplayWidth(run.Letters)
+		if run.Type != LetterRunTypes.SEPARATORRUN {
+			res = append(res, wordSpan{run: run, index: index, column: column, end: column + width})
+		}
+		column += width
+	}
+
+	return res
+}
+
+// looksLikeChord reports whether a word that isn't a chord is written like
+// one: a capital note name followed by numbers or chord punctuation, as a
+// chord with a suffix that isn't known would be
+func looksLikeChord(s string) bool {
+	return s[0] >= 'A' && s[0] <= 'G' && strings.ContainsAny(s[1:], "0123456789#+/()"+chordSymbols)
+}
+
+// sectionsWithLyrics returns whether each line is in a section with lyrics
+func (p *ParsedContent) sectionsWithLyrics() []bool {
+	res := make([]bool, len(p.Lines))
+	start := 0
+	for index := 0; index <= len(p.Lines); index++ {
+		if index < len(p.Lines) && p.Lines[index].Type != LineTypes.SECTION {
+			continue
+		}
+
+		hasLyrics := false
+		for _, line := range p.Lines[start:index] {
+			hasLyrics = hasLyrics || line.Type == LineTypes.LYRICS
+		}
+		for inSection := start; inSection < index; inSection++ {
+			res[inSection] = hasLyrics
+		}
+		start = index
+	}
+
+	return res
+}
+
+// diagnose collects warnings about the lines which may have been misread
+func (p *ParsedContent) diagnose() {
+	p.Diagnostics = make([]Diagnostic, 0)
+	warn := func(line int, column int, end int, kind string, format string, args ...any) {
+		p.Diagnostics = append(p.Diagnostics, Diagnostic{
+			LineNumber: line,
+			Column:     column,
+			EndColumn:  end,
+			Kind:       kind,
+			Message:    fmt.Sprintf(format, args...),
+		})
+	}
+
+	// unknownWords warns about the words which look like chords that
+	// aren't known, and returns how many others there are
+	unknownWords := func(index int, words []wordSpan) int {
+		others := 0
+		for _, word := range words {
+			if isChord(word.run.Letters) {
+				continue
+			}
+
+			if looksLikeChord(word.run.Letters) {
+				warn(index, word.column, word.end, DiagnosticUnknownChord, "%q looks like a chord, but isn't one that is known", word.run.Letters)
+			} else {
+				others += 1
+			}
+		}
+
+		return others
+	}
+
+	withLyrics := p.sectionsWithLyrics()
+	for index := range p.Lines {
+		line := &p.Lines[index]
+		if line.Overridden {
+			continue
+		}
+
+		switch line.Type {
+		case LineTypes.CHORDS:
+			if line.Markup != "" {
+				for _, word := range wordSpans(line.Parts) {
+					if word.run.Type == LetterRunTypes.CHORDRUN && word.run.Chord.Note == "" && strings.ToLower(word.run.Letters) != "n.c." {
+						warn(index, word.column, word.end, DiagnosticEmptyChord, "%q is marked as a chord, but can't be read as one", word.run.Letters)
+					}
+				}
+			} else {
+				words := lo.Filter(wordSpans(line.Parts), func(word wordSpan, _ int) bool {
+					return word.run.Type != LetterRunTypes.ANNOTATIONRUN
+				})
+				if unknownWords(index, words) > 0 {
+					warn(index, 0, DisplayWidth(line.String()), DiagnosticMixedLine, "This line has both chords and words in it")
+				}
+			}
+
+			next := index + 1
+			if withLyrics[index] && (next >= len(p.Lines) || p.Lines[next].Type != LineTypes.LYRICS) {
+				warn(index, 0, DisplayWidth(line.String()), DiagnosticNoLyrics, "There are no lyrics under these chords")
+			}
+		case LineTypes.LYRICS:
+			words := lo.Filter(wordSpans(chordLineRuns(line.Text)), func(word wordSpan, _ int) bool {
+				return word.run.Type != LetterRunTypes.ANNOTATIONRUN
+			})
+			chords := 0
+			for _, word := range words {
+				if isChord(word.run.Letters) {
+					chords += 1
+				}
+			}
+
+			if chords < 2 || chords*2 < len(words) {
+				break
+			}
+
+			if unknownWords(index, words) > 0 {
+				warn(index, 0, DisplayWidth(line.Text), DiagnosticMixedLine, "This line has both chords and words in it")
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiagnostics(t *testing.T) {
+	content := ParsedContent{}
+	err := content.ParseContent(`[Intro]
+C   G
+
+[Verse]
+C   G   Cadd13   Am
+Here is a lyric
+C   G
+Am  F
+Words here
+
+[Bridge]
+[ch]Riff[/ch]  [ch]G[/ch]
+Sing it out
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Diagnostic{
+		{LineNumber: 4, Column: 8, EndColumn: 14, Kind: DiagnosticUnknownChord, Message: `"Cadd13" looks like a chord, but isn't one that is known`},
+		{LineNumber: 6, Column: 0, EndColumn: 5, Kind: DiagnosticNoLyrics, Message: "There are no lyrics under these chords"},
+		{LineNumber: 11, Column: 0, EndColumn: 4, Kind: DiagnosticEmptyChord, Message: `"Riff" is marked as a chord, but can't be read as one`},
+	}
+	if !reflect.DeepEqual(content.Diagnostics, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, content.Diagnostics)
+	}
+
+	confidence := make([]float64, len(content.Lines))
+	for index, line := range content.Lines {
+		confidence[index] = line.Confidence
+	}
+
+	expectedConfidence := []float64{1, 1, 1, 1, 0.9, 1, 1, 1, 1, 1, 1, 1, 1}
+	if !reflect.DeepEqual(confidence, expectedConfidence) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expectedConfidence, confidence)
+	}
+}
+
+func TestDiagnosticsMixedLine(t *testing.T) {
+	content := ParsedContent{}
+	err := content.ParseContent("C   G   walking   Am\nSome words\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Diagnostic{
+		{LineNumber: 0, Column: 0, EndColumn: 20, Kind: DiagnosticMixedLine, Message: "This line has both chords and words in it"},
+	}
+	if !reflect.DeepEqual(content.Diagnostics, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, content.Diagnostics)
+	}
+}
@@ -9,4 +9,5 @@ const (
 	ChordRun
 	SeparatorRun
 	UnknownRun
+	AnnotationRun
 )
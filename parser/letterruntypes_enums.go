@@ -17,10 +17,11 @@ type LetterRunType struct {
 }
 
 type letterruntypesContainer struct {
-	WORDRUN      LetterRunType
-	CHORDRUN     LetterRunType
-	SEPARATORRUN LetterRunType
-	UNKNOWNRUN   LetterRunType
+	WORDRUN       LetterRunType
+	CHORDRUN      LetterRunType
+	SEPARATORRUN  LetterRunType
+	UNKNOWNRUN    LetterRunType
+	ANNOTATIONRUN LetterRunType
 }
 
 var LetterRunTypes = letterruntypesContainer{
@@ -36,6 +37,9 @@ var LetterRunTypes = letterruntypesContainer{
 	UNKNOWNRUN: LetterRunType{
 		letterRunType: UnknownRun,
 	},
+	ANNOTATIONRUN: LetterRunType{
+		letterRunType: AnnotationRun,
+	},
 }
 
 func (c letterruntypesContainer) All() []LetterRunType {
@@ -44,6 +48,7 @@ func (c letterruntypesContainer) All() []LetterRunType {
 		c.CHORDRUN,
 		c.SEPARATORRUN,
 		c.UNKNOWNRUN,
+		c.ANNOTATIONRUN,
 	}
 }
 
@@ -80,6 +85,8 @@ func stringToLetterRunType(s string) LetterRunType {
 		return LetterRunTypes.SEPARATORRUN
 	case "UnknownRun":
 		return LetterRunTypes.UNKNOWNRUN
+	case "AnnotationRun":
+		return LetterRunTypes.ANNOTATIONRUN
 	}
 	return invalidLetterRunType
 }
@@ -98,10 +105,11 @@ func ExhaustiveLetterRunTypes(f func(LetterRunType)) {
 }
 
 var validLetterRunTypes = map[LetterRunType]bool{
-	LetterRunTypes.WORDRUN:      true,
-	LetterRunTypes.CHORDRUN:     true,
-	LetterRunTypes.SEPARATORRUN: true,
-	LetterRunTypes.UNKNOWNRUN:   true,
+	LetterRunTypes.WORDRUN:       true,
+	LetterRunTypes.CHORDRUN:      true,
+	LetterRunTypes.SEPARATORRUN:  true,
+	LetterRunTypes.UNKNOWNRUN:    true,
+	LetterRunTypes.ANNOTATIONRUN: true,
 }
 
 func (p LetterRunType) IsValid() bool {
@@ -144,11 +152,12 @@ func _() {
 	_ = x[ChordRun-1]
 	_ = x[SeparatorRun-2]
 	_ = x[UnknownRun-3]
+	_ = x[AnnotationRun-4]
 }
 
-const _letterruntypes_name = "WordRunChordRunSeparatorRunUnknownRun"
+const _letterruntypes_name = "WordRunChordRunSeparatorRunUnknownRunAnnotationRun"
 
-var _letterruntypes_index = [...]uint16{0, 7, 15, 27, 37}
+var _letterruntypes_index = [...]uint16{0, 7, 15, 27, 37, 50}
 
 func (i letterRunType) String() string {
 	if i < 0 || i >= letterRunType(len(_letterruntypes_index)-1) {
@@ -0,0 +1,26 @@
+package parser
+
+// Metadata is information about a song which isn't part of the chart,
+// such as is kept in OnSong and OpenSong files
+type Metadata struct {
+	Title         string
+	Artist        string
+	Key           string
+	Tempo         string
+	TimeSignature string
+	Capo          string
+	Flow          []string
+}
+
+// Transposed returns the metadata with the key moved by the given number of half steps
+func (m Metadata) Transposed(steps int) Metadata {
+	chord := MakeChord(m.Key)
+	if chord.Note == "" {
+		return m
+	}
+
+	chord.Transpose(steps)
+	m.Key = chord.String()
+
+	return m
+}
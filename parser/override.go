@@ -0,0 +1,74 @@
+package parser
+
+import "strings"
+
+// Override sets the type of a line the parser reads wrongly, such as the
+// lyric "A B C D E F G", which looks like chords. It is matched to lines
+// by what they say rather than where they are, so it stays with its line
+// when lines are added or removed around it, and applies to each line which
+// says the same thing.
+type Override struct {
+	Text string   `json:"text"`
+	Type LineType `json:"type"`
+}
+
+// overrideKey returns what a line is matched to overrides by
+func overrideKey(text string) string {
+	return strings.TrimSpace(text)
+}
+
+// MakeOverride returns an override giving the line with the given text the
+// given type
+func MakeOverride(text string, lineType LineType) Override {
+	return Override{Text: overrideKey(text), Type: lineType}
+}
+
+// override returns the type the line with the given text has been given
+func (p *ParsedContent) override(text string) (LineType, bool) {
+	key := overrideKey(text)
+	if key == "" {
+		return LineType{}, false
+	}
+
+	for _, override := range p.Overrides {
+		if override.Text == key {
+			return override.Type, true
+		}
+	}
+
+	return LineType{}, false
+}
+
+// overrideLine gives the line the type it has been given, and the parts
+// that type needs
+func overrideLine(line *Line, lineType LineType) {
+	switch {
+	case lineType != LineTypes.CHORDS:
+		line.Parts = makeLetterRuns("")
+	case line.Type != LineTypes.CHORDS:
+		line.Parts = chordLineRuns(line.Text)
+	}
+
+	line.Type = lineType
+	line.Overridden = true
+}
+
+// WithOverride returns the overrides with the given one added, replacing
+// any for the same text
+func WithOverride(overrides []Override, override Override) []Override {
+	res := WithoutOverride(overrides, override.Text)
+
+	return append(res, override)
+}
+
+// WithoutOverride returns the overrides without any for the given text
+func WithoutOverride(overrides []Override, text string) []Override {
+	res := make([]Override, 0, len(overrides))
+	for _, existing := range overrides {
+		if existing.Text != overrideKey(text) {
+			res = append(res, existing)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func lineTypes(content ParsedContent) []LineType {
+	res := make([]LineType, len(content.Lines))
+	for index, line := range content.Lines {
+		res[index] = line.Type
+	}
+
+	return res
+}
+
+func TestOverrides(t *testing.T) {
+	content := ParsedContent{Overrides: []Override{
+		MakeOverride("  A B C D E F G ", LineTypes.LYRICS),
+		MakeOverride("Riff here", LineTypes.CHORDS),
+	}}
+	err := content.ParseContent("[Verse]\nA B C D E F G\nC  G\nRiff here\nA B C D E F G\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []LineType{LineTypes.SECTION, LineTypes.LYRICS, LineTypes.CHORDS, LineTypes.CHORDS, LineTypes.LYRICS}
+	if !reflect.DeepEqual(lineTypes(content), expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, lineTypes(content))
+	}
+
+	if !content.Lines[1].Overridden || content.Lines[2].Overridden || !content.Lines[4].Overridden {
+		t.Errorf("Expected only the overridden lines to be marked")
+	}
+
+	for _, diagnostic := range content.Diagnostics {
+		if content.Lines[diagnostic.LineNumber].Overridden {
+			t.Errorf("Expected no doubts about overridden lines, got %#v", diagnostic)
+		}
+	}
+
+	content.Transpose(2)
+	if content.Lines[3].String() != "Riff here" {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", "Riff here", content.Lines[3].String())
+	}
+}
+
+func TestWithOverride(t *testing.T) {
+	overrides := WithOverride(nil, MakeOverride("A day", LineTypes.LYRICS))
+	overrides = WithOverride(overrides, MakeOverride("C D", LineTypes.TEXT))
+	overrides = WithOverride(overrides, MakeOverride(" A day", LineTypes.TEXT))
+
+	expected := []Override{{Text: "C D", Type: LineTypes.TEXT}, {Text: "A day", Type: LineTypes.TEXT}}
+	if !reflect.DeepEqual(overrides, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, overrides)
+	}
+
+	overrides = WithoutOverride(overrides, "C D ")
+	if !reflect.DeepEqual(overrides, expected[1:]) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected[1:], overrides)
+	}
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"strings"
 	"unicode"
 
@@ -15,23 +16,37 @@ type LetterRun struct {
 	OriginalLetters   string
 	Chord             Chord
 	TransposedLetters string
+	Beats             float64
+	Bars              float64
+	Push              bool
+	Diamond           bool
+	Comment           string
 }
 
 type Line struct {
 	LineNumber int
 	Text       string
+	Markup     string
 	Parts      []LetterRun
 	Type       LineType
+	Repeat     int
+	Confidence float64
+	Overridden bool
 }
 
 type ParsedContent struct {
-	Lines []Line
+	Lines       []Line
+	Metadata    Metadata
+	Diagnostics []Diagnostic
+	Overrides   []Override
+	TabWidth    int
 }
 
+var ErrNoContent = errors.New("there is no content to parse")
+
 var knownChordSuffixes map[string]bool
 
 var chordLetters map[rune]bool
-var nextChordLetter map[rune]rune
 var separators map[rune]bool
 
 func init() {
@@ -51,15 +66,8 @@ func init() {
 		chordLetters[ch] = true
 	}
 
-	nextChordLetter = make(map[rune]rune)
-	for _, ch := range "ABCDEFG" {
+	for _, ch := range "ABCDEFG" + chordSymbols + strings.ToLower(chordSymbols) {
 		chordLetters[ch] = true
-
-		if ch < 'G' {
-			nextChordLetter[ch] = ch + 1
-		} else {
-			nextChordLetter[ch] = 'A'
-		}
 	}
 
 	separators = make(map[rune]bool)
@@ -98,8 +106,12 @@ func firstNonBlankChar(s string) (rune, bool) {
 }
 
 func isChord(s string) bool {
-	s = strings.ToLower(s)
+	s = strings.ToLower(accidentalSymbols.Replace(s))
 	found := false
+	if s == "" {
+		return false
+	}
+
 	if s == "n.c." {
 		return true
 	}
@@ -129,26 +141,12 @@ func isChord(s string) bool {
 			return true
 		}
 
-		_, found = knownChordSuffixes[s[start:]]
+		_, found = knownChordSuffixes[chordSuffix(s[start:])]
 	}
 
 	return found
 }
 
-func allAreChords(s []LetterRun) bool {
-	foundOneChord := false
-	allAreChordOrSeparators := lo.Reduce(s, func(agg bool, item LetterRun, _ int) bool {
-		isAChord := isChord(item.Letters)
-		if isAChord {
-			foundOneChord = true
-		}
-
-		return agg && isAChord || agg && item.Type == LetterRunTypes.SEPARATORRUN
-	}, true)
-
-	return foundOneChord && allAreChordOrSeparators
-}
-
 func makeOneLetterRun(text string, typ LetterRunType) LetterRun {
 	if typ == LetterRunTypes.CHORDRUN {
 		return LetterRun{Letters: text, Type: typ, Chord: MakeChord(text)}
@@ -162,21 +160,22 @@ func makeLetterRuns(s string) []LetterRun {
 
 	currentText := ""
 	currentType := LetterRunTypes.UNKNOWNRUN
-	for index, ch := range strings.ToLower(s) {
+	graphemes(s, func(cluster string, _ int) {
+		ch := unicode.ToLower([]rune(cluster)[0])
 		if _, found := separators[ch]; found {
 			if currentType == LetterRunTypes.SEPARATORRUN {
-				currentText += string(s[index])
+				currentText += cluster
 			} else {
 				if len(currentText) > 0 {
 					res = append(res, makeOneLetterRun(currentText, currentType))
 				}
-				currentText = string(s[index])
+				currentText = cluster
 				currentType = LetterRunTypes.SEPARATORRUN
 			}
 		} else {
 			if _, found := chordLetters[ch]; found {
 				if currentType == LetterRunTypes.CHORDRUN || currentType == LetterRunTypes.WORDRUN {
-					currentText += string(s[index])
+					currentText += cluster
 				} else {
 					if len(currentText) > 0 {
 						run := makeOneLetterRun(currentText, currentType)
@@ -185,7 +184,7 @@ func makeLetterRuns(s string) []LetterRun {
 						}
 						res = append(res, run)
 					}
-					currentText = string(s[index])
+					currentText = cluster
 					currentType = LetterRunTypes.CHORDRUN
 				}
 			} else {
@@ -193,10 +192,10 @@ func makeLetterRuns(s string) []LetterRun {
 					if len(currentText) == 0 {
 						currentType = LetterRunTypes.SEPARATORRUN
 					}
-					currentText += string(s[index])
+					currentText += cluster
 				} else {
 					if currentType == LetterRunTypes.CHORDRUN || currentType == LetterRunTypes.WORDRUN {
-						currentText += string(s[index])
+						currentText += cluster
 					} else {
 						if len(currentText) > 0 {
 							run := makeOneLetterRun(currentText, currentType)
@@ -205,13 +204,13 @@ func makeLetterRuns(s string) []LetterRun {
 							}
 							res = append(res, run)
 						}
-						currentText = string(s[index])
+						currentText = cluster
 					}
 					currentType = LetterRunTypes.WORDRUN
 				}
 			}
 		}
-	}
+	})
 
 	if len(currentText) > 0 {
 		run := makeOneLetterRun(currentText, currentType)
@@ -226,7 +225,7 @@ func makeLetterRuns(s string) []LetterRun {
 
 func (p *ParsedContent) importContent(content string) error {
 	p.Lines = lo.Map(strings.Split(content, "\n"), func(s string, _ int) Line {
-		res := strings.TrimRight(s, " \t\r\n")
+		res := strings.TrimRight(ExpandTabs(s, p.tabWidth()), " \r\n")
 		return Line{Text: res, Type: LineTypes.TEXT}
 	})
 
@@ -234,7 +233,18 @@ func (p *ParsedContent) importContent(content string) error {
 }
 
 func (p *ParsedContent) categorizeLines() error {
+	unknown := make([]int, 0)
 	for index := range p.Lines {
+		p.Lines[index].Confidence = 1
+		if lineType, found := p.override(p.Lines[index].Text); found {
+			overrideLine(&p.Lines[index], lineType)
+			continue
+		}
+
+		if p.Lines[index].Markup != "" {
+			continue
+		}
+
 		first, found := firstNonBlankChar(p.Lines[index].Text)
 		if found && first == '[' {
 			p.Lines[index].Type = LineTypes.SECTION
@@ -248,15 +258,11 @@ func (p *ParsedContent) categorizeLines() error {
 			continue
 		}
 
-		parts := makeLetterRuns(p.Lines[index].Text)
-		if allAreChords(parts) {
-			p.Lines[index].Type = LineTypes.CHORDS
-			p.Lines[index].Parts = parts
-		} else {
-			p.Lines[index].Type = LineTypes.LYRICS
-			p.Lines[index].Parts = makeLetterRuns("")
-		}
+		unknown = append(unknown, index)
 	}
+
+	p.classifyLines(unknown)
+
 	return nil
 }
 
@@ -271,6 +277,10 @@ func (p *ParsedContent) compactLines() error {
 		return true
 	})
 
+	if len(p.Lines) == 0 {
+		return ErrNoContent
+	}
+
 	if p.Lines[len(p.Lines)-1].Type == LineTypes.EMPTY {
 		p.Lines = p.Lines[:len(p.Lines)-1]
 	}
@@ -286,7 +296,12 @@ func (p *ParsedContent) compactLines() error {
 }
 
 func (p *ParsedContent) ParseContent(content string) error {
-	err := p.importContent(content)
+	var err error
+	if hasUltimateGuitarMarkup(content) {
+		err = p.importUltimateGuitar(content)
+	} else {
+		err = p.importContent(content)
+	}
 	if err != nil {
 		return err
 	}
@@ -301,6 +316,14 @@ func (p *ParsedContent) ParseContent(content string) error {
 		return err
 	}
 
+	for index := range p.Lines {
+		if p.Lines[index].Type == LineTypes.CHORDS {
+			annotateRhythm(&p.Lines[index])
+		}
+	}
+
+	p.diagnose()
+
 	return nil
 }
 
@@ -316,15 +339,15 @@ func (p *ParsedContent) TransposeUpOneStep() {
 			longer := make([]int, 0)
 			shorter := make([]int, 0)
 			for partIndex := range p.Lines[lineIndex].Parts {
-				if p.Lines[lineIndex].Parts[partIndex].Type == LetterRunTypes.CHORDRUN {
+				if p.Lines[lineIndex].Parts[partIndex].Type == LetterRunTypes.CHORDRUN && p.Lines[lineIndex].Parts[partIndex].Chord.Note != "" {
 					p.Lines[lineIndex].Parts[partIndex].Chord.StepUp()
 					newLetters := p.Lines[lineIndex].Parts[partIndex].Chord.String()
 					p.Lines[lineIndex].Parts[partIndex].TransposedLetters = newLetters
 
-					if len(newLetters) > len(p.Lines[lineIndex].Parts[partIndex].Chord.OriginalString) {
+					if DisplayWidth(newLetters) > DisplayWidth(p.Lines[lineIndex].Parts[partIndex].Chord.OriginalString) {
 						longer = append(longer, partIndex)
 					} else {
-						if len(newLetters) < len(p.Lines[lineIndex].Parts[partIndex].Chord.OriginalString) {
+						if DisplayWidth(newLetters) < DisplayWidth(p.Lines[lineIndex].Parts[partIndex].Chord.OriginalString) {
 							shorter = append(shorter, partIndex)
 						}
 					}
@@ -363,15 +386,15 @@ func (p *ParsedContent) TransposeDownOneStep() {
 			longer := make([]int, 0)
 			shorter := make([]int, 0)
 			for partIndex := range p.Lines[lineIndex].Parts {
-				if p.Lines[lineIndex].Parts[partIndex].Type == LetterRunTypes.CHORDRUN {
+				if p.Lines[lineIndex].Parts[partIndex].Type == LetterRunTypes.CHORDRUN && p.Lines[lineIndex].Parts[partIndex].Chord.Note != "" {
 					p.Lines[lineIndex].Parts[partIndex].Chord.StepDown()
 					newLetters := p.Lines[lineIndex].Parts[partIndex].Chord.String()
 					p.Lines[lineIndex].Parts[partIndex].TransposedLetters = newLetters
 
-					if len(newLetters) > len(p.Lines[lineIndex].Parts[partIndex].Chord.OriginalString) {
+					if DisplayWidth(newLetters) > DisplayWidth(p.Lines[lineIndex].Parts[partIndex].Chord.OriginalString) {
 						longer = append(longer, partIndex)
 					} else {
-						if len(newLetters) < len(p.Lines[lineIndex].Parts[partIndex].Chord.OriginalString) {
+						if DisplayWidth(newLetters) < DisplayWidth(p.Lines[lineIndex].Parts[partIndex].Chord.OriginalString) {
 							shorter = append(shorter, partIndex)
 						}
 					}
@@ -398,24 +421,27 @@ func (p *ParsedContent) TransposeDownOneStep() {
 	}
 }
 
-func (p *ParsedContent) SwitchToNNS(key string) {
-	remap := make(map[string]string)
-	keyCh := ([]rune(key))[0]
-	numCh := '1'
-	for range 7 {
-		nextCh := nextChordLetter[keyCh]
-		remap[string(keyCh)] = string(numCh)
-		keyCh = nextCh
-		numCh += 1
+func (p *ParsedContent) Transpose(steps int) {
+	for range steps % 12 {
+		p.TransposeUpOneStep()
+	}
+
+	for range -steps % 12 {
+		p.TransposeDownOneStep()
 	}
+}
 
+// SwitchToNNS shows the chords as Nashville numbers in the given key, with
+// minor chords written with a dash, as 6-, if asked
+func (p *ParsedContent) SwitchToNNS(key string, minorDash bool) {
+	keyChord := MakeChord(key)
 	for lineIndex := range p.Lines {
 		if p.Lines[lineIndex].Type == LineTypes.CHORDS {
 			for partIndex := range p.Lines[lineIndex].Parts {
-				if p.Lines[lineIndex].Parts[partIndex].Type == LetterRunTypes.CHORDRUN {
-					first := strings.ToUpper(p.Lines[lineIndex].Parts[partIndex].Letters[:1])
-					rest := p.Lines[lineIndex].Parts[partIndex].Letters[1:]
-					p.Lines[lineIndex].Parts[partIndex].TransposedLetters = remap[first] + rest
+				part := &p.Lines[lineIndex].Parts[partIndex]
+				if part.Type == LetterRunTypes.CHORDRUN && part.Chord.Note != "" {
+					original := makeTaggedChord(part.Letters)
+					part.TransposedLetters = original.NashvilleNumber(keyChord, minorDash)
 				}
 			}
 		}
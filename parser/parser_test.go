@@ -44,20 +44,26 @@ func TestImportContent(t *testing.T) {
 	}
 }
 
-func TestAllAreChords(t *testing.T) {
-	if !allAreChords(makeLetterRuns("A D G")) {
+// readAsChords reports whether the classifier reads the text as a chord line
+// on its own, without its neighbours
+func readAsChords(text string) bool {
+	return chordScore(chordLineRuns(text)) >= chordThreshold
+}
+
+func TestReadAsChords(t *testing.T) {
+	if !readAsChords("A D G") {
 		t.Errorf("Chords found to not be chords")
 	}
 
-	if !allAreChords(makeLetterRuns("A#M DbMAJ7b5 GDim")) {
+	if !readAsChords("A#M DbMAJ7b5 GDim") {
 		t.Errorf("Chords with capitalized colors found to not be chords")
 	}
 
-	if !allAreChords(makeLetterRuns("A/C Db/Gb GDim/C")) {
+	if !readAsChords("A/C Db/Gb GDim/C") {
 		t.Errorf("Chords with inversions found to not be chords")
 	}
 
-	if !allAreChords(makeLetterRuns("N.C.")) {
+	if !readAsChords("N.C.") {
 		t.Errorf("N.C. marks found to not be chords")
 	}
 
@@ -65,16 +71,16 @@ func TestAllAreChords(t *testing.T) {
 		arr := lo.Map([]string{"A", "C#", "Gb"}, func(s string, _ int) string {
 			return s + suffix
 		})
-		if !allAreChords(makeLetterRuns(strings.Join(arr, " "))) {
+		if !readAsChords(strings.Join(arr, " ")) {
 			t.Errorf("Not all %s chords are chords", suffix)
 		}
 	}
 
-	if allAreChords(makeLetterRuns("Foo lyric dude")) {
+	if readAsChords("Foo lyric dude") {
 		t.Errorf("Lyrics found to be chords")
 	}
 
-	if allAreChords(makeLetterRuns("aaa e/e/e/e/e fmm#g")) {
+	if readAsChords("aaa e/e/e/e/e fmm#g") {
 		t.Errorf("Non-chords which have chord-allowed letters found to be chords")
 	}
 }
@@ -92,12 +98,12 @@ func TestCategorizeLines(t *testing.T) {
 	}
 
 	expected := []Line{
-		{Text: "", Type: LineTypes.EMPTY, Parts: makeLetterRuns("")},
-		{Text: "[Section]", Type: LineTypes.SECTION, Parts: makeLetterRuns("")},
-		{Text: "   C   D   E", Type: LineTypes.CHORDS, Parts: makeLetterRuns("   C   D   E")},
-		{Text: "Foo lyric lyric", Type: LineTypes.LYRICS, Parts: makeLetterRuns("")},
-		{Text: "a - B|C / / /| D E", Type: LineTypes.CHORDS, Parts: makeLetterRuns("a - B|C / / /| D E")},
-		{Text: "", Type: LineTypes.EMPTY, Parts: makeLetterRuns("")},
+		{Text: "", Type: LineTypes.EMPTY, Confidence: 1, Parts: makeLetterRuns("")},
+		{Text: "[Section]", Type: LineTypes.SECTION, Confidence: 1, Parts: makeLetterRuns("")},
+		{Text: "   C   D   E", Type: LineTypes.CHORDS, Confidence: 1, Parts: makeLetterRuns("   C   D   E")},
+		{Text: "Foo lyric lyric", Type: LineTypes.LYRICS, Confidence: 1, Parts: makeLetterRuns("")},
+		{Text: "a - B|C / / /| D E", Type: LineTypes.CHORDS, Confidence: 1, Parts: makeLetterRuns("a - B|C / / /| D E")},
+		{Text: "", Type: LineTypes.EMPTY, Confidence: 1, Parts: makeLetterRuns("")},
 	}
 	if !reflect.DeepEqual(parser.Lines, expected) {
 		t.Errorf("Expected:\n'%#v', got:\n'%#v'",
@@ -126,13 +132,13 @@ Spoken line
 	}
 
 	expected := []Line{
-		{Text: "", Type: LineTypes.EMPTY, Parts: makeLetterRuns("")},
-		{Text: "[Section]", Type: LineTypes.SECTION, Parts: makeLetterRuns("")},
-		{Text: "   C   D   E", Type: LineTypes.CHORDS, Parts: makeLetterRuns("   C   D   E")},
-		{Text: "Foo lyric lyric", Type: LineTypes.LYRICS, Parts: makeLetterRuns("")},
-		{Text: "N.C.   N.C.", Type: LineTypes.CHORDS, Parts: makeLetterRuns("N.C.   N.C.")},
-		{Text: "Spoken line", Type: LineTypes.LYRICS, Parts: makeLetterRuns("")},
-		{Text: "", Type: LineTypes.EMPTY, Parts: makeLetterRuns("")},
+		{Text: "", Type: LineTypes.EMPTY, Confidence: 1, Parts: makeLetterRuns("")},
+		{Text: "[Section]", Type: LineTypes.SECTION, Confidence: 1, Parts: makeLetterRuns("")},
+		{Text: "   C   D   E", Type: LineTypes.CHORDS, Confidence: 1, Parts: makeLetterRuns("   C   D   E")},
+		{Text: "Foo lyric lyric", Type: LineTypes.LYRICS, Confidence: 1, Parts: makeLetterRuns("")},
+		{Text: "N.C.   N.C.", Type: LineTypes.CHORDS, Confidence: 1, Parts: makeLetterRuns("N.C.   N.C.")},
+		{Text: "Spoken line", Type: LineTypes.LYRICS, Confidence: 1, Parts: makeLetterRuns("")},
+		{Text: "", Type: LineTypes.EMPTY, Confidence: 1, Parts: makeLetterRuns("")},
 	}
 	if !reflect.DeepEqual(parser.Lines, expected) {
 		t.Errorf("Expected:\n'%#v', got:\n'%#v'",
@@ -161,13 +167,13 @@ Line with sharp chords
 	}
 
 	expected := []Line{
-		{Text: "", Type: LineTypes.EMPTY, Parts: makeLetterRuns("")},
-		{Text: "[Section]", Type: LineTypes.SECTION, Parts: makeLetterRuns("")},
-		{Text: "   C   D   E", Type: LineTypes.CHORDS, Parts: makeLetterRuns("   C   D   E")},
-		{Text: "Foo lyric lyric", Type: LineTypes.LYRICS, Parts: makeLetterRuns("")},
-		{Text: "C#m7       Asus2/C#        C#m7", Type: LineTypes.CHORDS, Parts: makeLetterRuns("C#m7       Asus2/C#        C#m7")},
-		{Text: "Line with sharp chords", Type: LineTypes.LYRICS, Parts: makeLetterRuns("")},
-		{Text: "", Type: LineTypes.EMPTY, Parts: makeLetterRuns("")},
+		{Text: "", Type: LineTypes.EMPTY, Confidence: 1, Parts: makeLetterRuns("")},
+		{Text: "[Section]", Type: LineTypes.SECTION, Confidence: 1, Parts: makeLetterRuns("")},
+		{Text: "   C   D   E", Type: LineTypes.CHORDS, Confidence: 1, Parts: makeLetterRuns("   C   D   E")},
+		{Text: "Foo lyric lyric", Type: LineTypes.LYRICS, Confidence: 1, Parts: makeLetterRuns("")},
+		{Text: "C#m7       Asus2/C#        C#m7", Type: LineTypes.CHORDS, Confidence: 1, Parts: makeLetterRuns("C#m7       Asus2/C#        C#m7")},
+		{Text: "Line with sharp chords", Type: LineTypes.LYRICS, Confidence: 1, Parts: makeLetterRuns("")},
+		{Text: "", Type: LineTypes.EMPTY, Confidence: 1, Parts: makeLetterRuns("")},
 	}
 	if !reflect.DeepEqual(parser.Lines, expected) {
 		t.Errorf("Expected:\n'%#v', got:\n'%#v'",
@@ -203,11 +209,11 @@ Foo lyric lyric
 	}
 
 	expected := []Line{
-		{Text: "[Section]", Type: LineTypes.SECTION, LineNumber: 0, Parts: makeLetterRuns("")},
-		{Text: "", Type: LineTypes.EMPTY, LineNumber: 1, Parts: makeLetterRuns("")},
-		{Text: "   C   D   E", Type: LineTypes.CHORDS, LineNumber: 2, Parts: makeLetterRuns("   C   D   E")},
-		{Text: "", Type: LineTypes.EMPTY, LineNumber: 3, Parts: makeLetterRuns("")},
-		{Text: "Foo lyric lyric", Type: LineTypes.LYRICS, LineNumber: 4, Parts: makeLetterRuns("")},
+		{Text: "[Section]", Type: LineTypes.SECTION, Confidence: 1, LineNumber: 0, Parts: makeLetterRuns("")},
+		{Text: "", Type: LineTypes.EMPTY, Confidence: 1, LineNumber: 1, Parts: makeLetterRuns("")},
+		{Text: "   C   D   E", Type: LineTypes.CHORDS, Confidence: 1, LineNumber: 2, Parts: makeLetterRuns("   C   D   E")},
+		{Text: "", Type: LineTypes.EMPTY, Confidence: 1, LineNumber: 3, Parts: makeLetterRuns("")},
+		{Text: "Foo lyric lyric", Type: LineTypes.LYRICS, Confidence: 1, LineNumber: 4, Parts: makeLetterRuns("")},
 	}
 	if !reflect.DeepEqual(parser.Lines, expected) {
 		t.Errorf("Expected:\n")
@@ -286,6 +292,16 @@ func TestParseContent(t *testing.T) {
 	}
 }
 
+func TestParseEmptyContent(t *testing.T) {
+	for _, empty := range []string{"", "\n\n", "   \n\t\n"} {
+		parser := ParsedContent{}
+		err := parser.ParseContent(empty)
+		if err != ErrNoContent {
+			t.Errorf("Expected ErrNoContent for %#v, got %v", empty, err)
+		}
+	}
+}
+
 func TestLineString(t *testing.T) {
 	parser := ParsedContent{}
 	err := parser.ParseContent(content)
@@ -533,6 +549,52 @@ func TestTransposeUpAnOctave(t *testing.T) {
 	}
 }
 
+func TestTransposeBySteps(t *testing.T) {
+	up := ParsedContent{}
+	err := up.ParseContent(content)
+	if err != nil {
+		t.Error(err)
+	}
+
+	up.Transpose(14)
+
+	down := ParsedContent{}
+	err = down.ParseContent(content)
+	if err != nil {
+		t.Error(err)
+	}
+
+	down.Transpose(-3)
+
+	expectedUp := []string{
+		"[Section]",
+		"   D   E   F#",
+		"Foo lyric lyric",
+		"B - C#|D / / /| E F#",
+	}
+
+	expectedDown := []string{
+		"[Section]",
+		"   A   B   Db",
+		"Foo lyric lyric",
+		"Gb - Ab|A / / /| B Db",
+	}
+
+	for _, check := range []struct {
+		parsed   ParsedContent
+		expected []string
+	}{{up, expectedUp}, {down, expectedDown}} {
+		asString := make([]string, len(check.parsed.Lines))
+		for index, line := range check.parsed.Lines {
+			asString[index] = line.String()
+		}
+
+		if !reflect.DeepEqual(asString, check.expected) {
+			t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", check.expected, asString)
+		}
+	}
+}
+
 func TestSwitchToNNSFromC(t *testing.T) {
 	parser := ParsedContent{}
 	err := parser.ParseContent(content)
@@ -540,7 +602,7 @@ func TestSwitchToNNSFromC(t *testing.T) {
 		t.Error(err)
 	}
 
-	parser.SwitchToNNS("C")
+	parser.SwitchToNNS("C", false)
 
 	expected := []string{
 		"[Section]",
@@ -576,7 +638,7 @@ Eb F G Ab Bb C D
 //		t.Error(err)
 //	}
 //
-//	parser.SwitchToNNS("Eb")
+//	parser.SwitchToNNS("Eb", false)
 //
 //	expected := []string{
 //		"[Section]",
@@ -594,3 +656,97 @@ Eb F G Ab Bb C D
 //		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, asString)
 //	}
 //}
+
+func TestProgression(t *testing.T) {
+	content := ParsedContent{}
+	err := content.ParseContent("[Verse]\nC     G\nHello there world\nAm\n\n[Chorus]\nF\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content.TransposeUpOneStep()
+	res := lo.Map(content.Progression(), func(chord ProgressionChord, _ int) []any {
+		return []any{chord.Text, chord.Chord.Root(), chord.Section, chord.StartsSection, chord.Lyric}
+	})
+
+	expected := [][]any{
+		{"C#", 1, "Verse", true, "Hello"},
+		{"G#", 8, "Verse", false, "there world"},
+		{"A#m", 10, "Verse", false, ""},
+		{"F#", 6, "Chorus", true, ""},
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, res)
+	}
+}
+
+func TestArrangementFollowsFlow(t *testing.T) {
+	content := ParsedContent{}
+	err := content.ParseContent("[Verse 1]\nC\n[Chorus]\nF\n[Verse 2]\nG\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts := func() []string {
+		return lo.Map(content.Arrangement(), func(chord ProgressionChord, _ int) string {
+			return chord.Text
+		})
+	}
+
+	expected := []string{"C", "F", "G"}
+	if !reflect.DeepEqual(texts(), expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, texts())
+	}
+
+	content.Metadata.Flow = []string{"V1", "C", "Verse 2", "C", "Bridge"}
+	expected = []string{"C", "F", "G", "F"}
+	if !reflect.DeepEqual(texts(), expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, texts())
+	}
+
+	content.Metadata.Flow = []string{"Outro"}
+	expected = []string{"C", "F", "G"}
+	if !reflect.DeepEqual(texts(), expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, texts())
+	}
+}
+
+func TestArrangementPlaysRepeatedSectionsOnce(t *testing.T) {
+	content := ParsedContent{Metadata: Metadata{Flow: []string{"V", "C", "V", "C", "C"}}}
+	err := content.ParseContent("G\n[Verse]\nC\n[Chorus]\nF\n[Verse]\nAm\n[Chorus]\nDm\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts := lo.Map(content.Arrangement(), func(chord ProgressionChord, _ int) string {
+		return chord.Text
+	})
+
+	expected := []string{"G", "C", "F", "Am", "Dm", "Dm"}
+	if !reflect.DeepEqual(texts, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, texts)
+	}
+}
+
+func TestMetadataTempoAndMeter(t *testing.T) {
+	cases := []struct {
+		tempo    string
+		time     string
+		bpm      int
+		beats    int
+		beatType int
+	}{
+		{"120", "3/4", 120, 3, 4},
+		{"96 bpm", "6/8", 96, 6, 8},
+		{"fast", "7/5", 0, 4, 4},
+		{"", "", 0, 4, 4},
+	}
+
+	for _, c := range cases {
+		metadata := Metadata{Tempo: c.tempo, TimeSignature: c.time}
+		beats, beatType := metadata.Meter()
+		if metadata.BPM() != c.bpm || beats != c.beats || beatType != c.beatType {
+			t.Errorf("Expected %d and %d/%d for %#v, got %d and %d/%d", c.bpm, c.beats, c.beatType, metadata, metadata.BPM(), beats, beatType)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package parser
+
+import "strings"
+
+// PlacedChord is a chord on a chord line, with the column it starts at in
+// the line as it was written, which is the column of the lyric it goes with
+type PlacedChord struct {
+	Column int
+	Chord  string
+	Part   int
+}
+
+// ShownLetters returns the run's letters as they are currently shown,
+// without the padding transposition may add
+func (run LetterRun) ShownLetters() string {
+	if run.TransposedLetters != "" {
+		return strings.TrimRight(run.TransposedLetters, " ")
+	}
+
+	return run.Letters
+}
+
+// PlacedChords returns the chords of a chord line, in order
+func (line Line) PlacedChords() []PlacedChord {
+	res := make([]PlacedChord, 0)
+	column := 0
+	for index, part := range line.Parts {
+		width := DisplayWidth(part.Letters)
+		if part.Type == LetterRunTypes.SEPARATORRUN && part.OriginalLetters != "" {
+			width = DisplayWidth(part.OriginalLetters)
+		}
+
+		if part.Type == LetterRunTypes.CHORDRUN {
+			res = append(res, PlacedChord{Column: column, Chord: part.ShownLetters(), Part: index})
+		}
+
+		column += width
+	}
+
+	return res
+}
+
+// LyricsUnder splits a lyric line at the columns of the chords above it.
+// It returns the lyrics before the first chord, and those under each chord.
+func LyricsUnder(chords []PlacedChord, lyric string) (string, []string) {
+	width := DisplayWidth(lyric)
+	leading := ""
+	if len(chords) > 0 {
+		leading = SliceColumns(lyric, 0, chords[0].Column)
+	}
+
+	res := make([]string, len(chords))
+	for index, chord := range chords {
+		end := width
+		if index+1 < len(chords) {
+			end = chords[index+1].Column
+		}
+
+		res[index] = SliceColumns(lyric, chord.Column, end)
+	}
+
+	return leading, res
+}
+
+// ReplaceChord changes the letters of one chord in a chord line's runs. The
+// space after it grows or shrinks to match, as far as it can while keeping
+// a space, so the chords after it stay in their columns.
+func ReplaceChord(parts []LetterRun, index int, letters string) {
+	old := parts[index].Letters
+	parts[index].Letters = letters
+	difference := DisplayWidth(letters) - DisplayWidth(old)
+	if index >= len(parts)-1 || parts[index+1].Type != LetterRunTypes.SEPARATORRUN {
+		return
+	}
+
+	next := parts[index+1].Letters
+	if difference < 0 {
+		parts[index+1].Letters = strings.Repeat(" ", -difference) + next
+		return
+	}
+
+	trimmed := strings.TrimLeft(next, " ")
+	spaces := len(next) - len(trimmed)
+	remove := min(difference, spaces-1)
+	if remove > 0 {
+		parts[index+1].Letters = next[remove:]
+	}
+}
+
+// ChordLineText returns the text of a chord line made of the given runs,
+// with its chords tagged if the line was written with markup
+func ChordLineText(parts []LetterRun, markup bool) string {
+	if markup {
+		return UltimateGuitarMarkup(parts)
+	}
+
+	res := ""
+	for _, part := range parts {
+		res += part.Letters
+	}
+
+	return res
+}
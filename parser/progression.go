@@ -0,0 +1,164 @@
+package parser
+
+import "strings"
+
+// ProgressionChord is one chord of the song, in the order the chart gives
+// them, along with the section it's in and the lyrics sung over it. Beats
+// is how long it lasts, or 0 when the chart doesn't say, and Comment is any
+// note written with it, like (let ring).
+type ProgressionChord struct {
+	Chord         Chord
+	Text          string
+	Section       string
+	StartsSection bool
+	Lyric         string
+	Beats         float64
+	Push          bool
+	Diamond       bool
+	Comment       string
+}
+
+// Progression returns the song's chords in order
+func (p ParsedContent) Progression() []ProgressionChord {
+	res := make([]ProgressionChord, 0)
+	beatsPerBar, _ := p.Metadata.Meter()
+	section := ""
+	sectionStarted := false
+	for index := 0; index < len(p.Lines); index++ {
+		line := p.Lines[index]
+		switch line.Type {
+		case LineTypes.SECTION:
+			section = strings.Trim(strings.TrimSpace(line.Text), "[]")
+			sectionStarted = false
+		case LineTypes.CHORDS:
+			placed := line.PlacedChords()
+			lyrics := make([]string, len(placed))
+			if index+1 < len(p.Lines) && p.Lines[index+1].Type == LineTypes.LYRICS {
+				_, lyrics = LyricsUnder(placed, p.Lines[index+1].Text)
+				index += 1
+			}
+
+			for time := range max(1, line.Repeat) {
+				for chordIndex, chord := range placed {
+					lyric := ""
+					if time == 0 {
+						lyric = strings.TrimSpace(lyrics[chordIndex])
+					}
+
+					res = append(res, ProgressionChord{
+						Chord:         line.Parts[chord.Part].Chord,
+						Text:          chord.Chord,
+						Section:       section,
+						StartsSection: section != "" && !sectionStarted,
+						Lyric:         lyric,
+						Beats:         line.Parts[chord.Part].Duration(beatsPerBar),
+						Push:          line.Parts[chord.Part].Push,
+						Diamond:       line.Parts[chord.Part].Diamond,
+						Comment:       line.Parts[chord.Part].Comment,
+					})
+					sectionStarted = true
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+// SectionAbbreviation returns the short name a song's flow can use for a
+// section, as OpenSong does: the first letter of its name and its number,
+// so "Verse 2" is "v2"
+func SectionAbbreviation(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return ""
+	}
+
+	digits := strings.TrimLeft(name[len(strings.TrimRight(name, "0123456789")):], " ")
+
+	return name[:1] + digits
+}
+
+// Arrangement returns the song's chords in the order they're played,
+// following the flow in its metadata. Each entry of the flow plays the next
+// section with its name, or the last one if none come after. Sections the
+// flow names that aren't in the song are skipped, and any chords before the
+// first section come first. Without a flow, or if it names none of the
+// sections, the chords are in the order the chart gives them.
+func (p ParsedContent) Arrangement() []ProgressionChord {
+	progression := p.Progression()
+	if len(p.Metadata.Flow) == 0 {
+		return progression
+	}
+
+	type occurrence struct {
+		name   string
+		chords []ProgressionChord
+	}
+
+	lead := make([]ProgressionChord, 0)
+	occurrences := make([]occurrence, 0)
+	for _, chord := range progression {
+		switch {
+		case chord.StartsSection:
+			occurrences = append(occurrences, occurrence{name: chord.Section, chords: []ProgressionChord{chord}})
+		case len(occurrences) == 0:
+			lead = append(lead, chord)
+		default:
+			occurrences[len(occurrences)-1].chords = append(occurrences[len(occurrences)-1].chords, chord)
+		}
+	}
+
+	byName := func(entry string) func(name string) bool {
+		key := strings.ToLower(strings.ReplaceAll(entry, " ", ""))
+		return func(name string) bool {
+			return strings.ToLower(strings.ReplaceAll(name, " ", "")) == key
+		}
+	}
+	byAbbreviation := func(entry string) func(name string) bool {
+		key := strings.ToLower(strings.ReplaceAll(entry, " ", ""))
+		return func(name string) bool {
+			return SectionAbbreviation(name) == key
+		}
+	}
+
+	// find returns the next occurrence from the given one which matches the
+	// entry, or the last which does, or -1 if none do
+	find := func(entry string, from int) int {
+		for _, matches := range []func(string) bool{byName(entry), byAbbreviation(entry)} {
+			last := -1
+			for index, section := range occurrences {
+				if !matches(section.name) {
+					continue
+				}
+
+				if index >= from {
+					return index
+				}
+				last = index
+			}
+
+			if last >= 0 {
+				return last
+			}
+		}
+
+		return -1
+	}
+
+	res := make([]ProgressionChord, 0)
+	next := 0
+	for _, entry := range p.Metadata.Flow {
+		index := find(entry, next)
+		if index >= 0 {
+			res = append(res, occurrences[index].chords...)
+			next = index + 1
+		}
+	}
+
+	if len(res) == 0 {
+		return progression
+	}
+
+	return append(lead, res...)
+}
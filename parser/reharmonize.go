@@ -0,0 +1,329 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// TritoneSubstitution replaces each dominant seventh with the one a
+	// tritone away, so G7 becomes Db7
+	TritoneSubstitution = "tritone"
+	// DiatonicSevenths adds the seventh the key gives each plain triad, so in
+	// C, C becomes Cmaj7, Dm becomes Dm7 and G becomes G7
+	DiatonicSevenths = "sevenths"
+	// SimplifyToTriads strips each chord down to its triad, so Cmaj9 becomes C
+	SimplifyToTriads = "triads"
+	// RelativeSwap swaps each major triad for its relative minor, and each
+	// minor triad for its relative major
+	RelativeSwap = "relative"
+	// BassWalkDown puts a chord over the bass note which walks down by step
+	// from the chord before it to the chord after it, so C G Am becomes C G/B Am
+	BassWalkDown = "walkdown"
+)
+
+// Reharmonizations are the ways a song's chords can be rewritten
+var Reharmonizations = []string{TritoneSubstitution, DiatonicSevenths, SimplifyToTriads, RelativeSwap, BassWalkDown}
+
+var reharmonizers = map[string]func(chords []Chord, key Chord) []Chord{
+	TritoneSubstitution: mapChords(tritoneSubstitute),
+	DiatonicSevenths:    mapChords(diatonicSeventh),
+	SimplifyToTriads:    mapChords(func(chord Chord, _ Chord) Chord { return chord.Triad() }),
+	RelativeSwap:        mapChords(relative),
+	BassWalkDown:        walkDown,
+}
+
+// ChordChange is one chord a reharmonization changes, given by the line and
+// run it is in, as it was written and as it would be
+type ChordChange struct {
+	LineNumber int
+	Part       int
+	From       string
+	To         string
+}
+
+func mapChords(change func(chord Chord, key Chord) Chord) func(chords []Chord, key Chord) []Chord {
+	return func(chords []Chord, key Chord) []Chord {
+		res := make([]Chord, len(chords))
+		for index, chord := range chords {
+			res[index] = change(chord, key)
+		}
+
+		return res
+	}
+}
+
+var sharpNames = []string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+var flatNames = []string{"C", "Db", "D", "Eb", "E", "F", "Gb", "G", "Ab", "A", "Bb", "B"}
+
+// spell returns a note of the given pitch class, written with the letter the
+// given number of letters above the note's, as an interval would be. When
+// that would need more than one sharp or flat, or a Cb, Fb, E# or B#, the
+// pitch is written the plainer way instead.
+func spell(note Chord, letters int, pitch int) Chord {
+	pitch = (pitch%12 + 12) % 12
+	letter := noteLetters[((strings.Index(noteLetters, note.Note)+letters)%7+7)%7]
+	alter := (pitch-noteSemitones[string(letter)]+18)%12 - 6
+
+	res := Chord{Note: string(letter), Accidental: AccidentalTypes.NATURAL}
+	switch {
+	case alter == 0:
+	case alter == 1 && letter != 'E' && letter != 'B':
+		res.Accidental = AccidentalTypes.SHARP
+	case alter == -1 && letter != 'C' && letter != 'F':
+		res.Accidental = AccidentalTypes.FLAT
+	case note.Accidental == AccidentalTypes.SHARP:
+		res = MakeChord(sharpNames[pitch])
+	default:
+		res = MakeChord(flatNames[pitch])
+	}
+
+	res.Symbols = note.Symbols
+	res.OriginalString = res.String()
+
+	return res
+}
+
+// withRoot returns the chord with its root moved to the given note, keeping
+// its suffix and bass note
+func (c Chord) withRoot(root Chord) Chord {
+	c.Note, c.Accidental = root.Note, root.Accidental
+	return c
+}
+
+// withBass returns the chord over the given bass note, or with no bass note
+// if it is nil
+func (c Chord) withBass(bass *Chord) Chord {
+	c.BassNote = bass
+	return c
+}
+
+// Triad returns the chord stripped down to its triad, or its sus or power
+// chord, keeping its bass note. Chords which are already that, or whose
+// suffix isn't known, are returned as they are.
+func (c Chord) Triad() Chord {
+	quality := c.Quality()
+	if c.Note == "" || quality.Kind == "other" {
+		return c
+	}
+
+	intervals := quality.Intervals
+	flavor := ""
+	switch {
+	case slices.Contains(intervals, 3) && slices.Contains(intervals, 6) && !slices.Contains(intervals, 7):
+		flavor = "dim"
+	case slices.Contains(intervals, 3):
+		flavor = "m"
+	case slices.Contains(intervals, 4) && slices.Contains(intervals, 8) && !slices.Contains(intervals, 7):
+		flavor = "aug"
+	case slices.Contains(intervals, 4):
+		flavor = ""
+	case slices.Contains(intervals, 5):
+		flavor = "sus4"
+	case slices.Contains(intervals, 2):
+		flavor = "sus2"
+	default:
+		flavor = "5"
+	}
+
+	if chordSuffix(c.Flavor) == flavor || c.Flavor == flavor {
+		return c
+	}
+
+	c.Flavor = flavor
+
+	return c
+}
+
+func tritoneSubstitute(chord Chord, _ Chord) Chord {
+	if chord.Note == "" || !strings.HasPrefix(chord.Quality().Kind, "dominant") {
+		return chord
+	}
+
+	res := chord.withRoot(spell(chord, 4, chord.Root()+6))
+	if chord.BassNote != nil && chord.BassNote.Note != "" {
+		bass := spell(*chord.BassNote, 4, chord.BassNote.Root()+6)
+		res = res.withBass(&bass)
+	}
+
+	return res
+}
+
+// diatonicSevenths are the sevenths the major scale gives the triad on each
+// of its degrees, by the half steps from the key's root
+var diatonicSevenths = map[int]string{0: "maj7", 2: "m7", 4: "m7", 5: "maj7", 7: "7", 9: "m7", 11: "m7b5"}
+
+// majorKey returns the major key the given key shares its notes with, so a
+// minor key gives its relative major
+func majorKey(key Chord) Chord {
+	if key.Note == "" || key.Quality().Kind != "minor" {
+		return key
+	}
+
+	return spell(key, 2, key.Root()+3)
+}
+
+func diatonicSeventh(chord Chord, key Chord) Chord {
+	if chord.Note == "" {
+		return chord
+	}
+
+	flavor := chordSuffix(chord.Flavor)
+	if flavor != "" && flavor != "m" && flavor != "dim" {
+		return chord
+	}
+
+	seventh, diatonic := diatonicSevenths[(chord.Root()-majorKey(key).Root()+12)%12]
+	if key.Note == "" || !diatonic || (seventh == "m7") != (flavor == "m") || (seventh == "m7b5") != (flavor == "dim") {
+		// Outside the key a major chord is taken to be a dominant on its way
+		// somewhere, without one a major chord is the tonic
+		switch flavor {
+		case "":
+			seventh = "7"
+			if key.Note == "" {
+				seventh = "maj7"
+			}
+		case "m":
+			seventh = "m7"
+		case "dim":
+			seventh = "m7b5"
+		}
+	}
+
+	chord.Flavor = seventh
+
+	return chord
+}
+
+func relative(chord Chord, _ Chord) Chord {
+	switch {
+	case chord.Note == "":
+		return chord
+	case chord.Flavor == "":
+		res := chord.withRoot(spell(chord, -2, chord.Root()-3))
+		res.Flavor = "m"
+		return res
+	case chordSuffix(chord.Flavor) == "m":
+		res := chord.withRoot(spell(chord, 2, chord.Root()+3))
+		res.Flavor = ""
+		return res
+	}
+
+	return chord
+}
+
+// walkDown finds each chord which comes between two chords whose bass notes
+// are a few half steps apart, going down, and puts it over the note of its
+// own which is a step below the first and a step above the second
+func walkDown(chords []Chord, _ Chord) []Chord {
+	res := slices.Clone(chords)
+	isStep := func(from int, to int) bool {
+		difference := (from - to + 12) % 12
+		return difference == 1 || difference == 2
+	}
+
+	for index := 1; index < len(res)-1; index++ {
+		chord := res[index]
+		if chord.Note == "" || chord.Bass() != chord.Root() || res[index-1].Note == "" || res[index+1].Note == "" {
+			continue
+		}
+
+		above, below := res[index-1].Bass(), res[index+1].Bass()
+		for _, tone := range chord.Tones()[1:] {
+			if isStep(above, tone) && isStep(tone, below) {
+				previous := res[index-1]
+				if previous.BassNote != nil && previous.BassNote.Note != "" {
+					previous = *previous.BassNote
+				}
+
+				bass := spell(previous, -1, tone)
+				res[index] = chord.withBass(&bass)
+				break
+			}
+		}
+	}
+
+	return res
+}
+
+// ReharmonizeLines works out how the given reharmonization would change the
+// chords on the lines from first to last. The key is used by those which
+// need one, and may be left empty.
+func (p ParsedContent) ReharmonizeLines(technique string, key string, first int, last int) ([]ChordChange, error) {
+	reharmonize, found := reharmonizers[technique]
+	if !found {
+		return nil, fmt.Errorf("%#v is not a reharmonization", technique)
+	}
+
+	keyChord := MakeChord(key)
+	if key != "" && keyChord.Note == "" {
+		return nil, fmt.Errorf("%#v is not a key", key)
+	}
+
+	type place struct {
+		line int
+		part int
+	}
+	places := make([]place, 0)
+	chords := make([]Chord, 0)
+	for index := max(0, first); index <= last && index < len(p.Lines); index++ {
+		line := p.Lines[index]
+		if line.Type != LineTypes.CHORDS {
+			continue
+		}
+
+		for partIndex, part := range line.Parts {
+			if part.Type == LetterRunTypes.CHORDRUN && part.Chord.Note != "" {
+				places = append(places, place{line: index, part: partIndex})
+				chords = append(chords, part.Chord)
+			}
+		}
+	}
+
+	res := make([]ChordChange, 0)
+	for index, chord := range reharmonize(chords, keyChord) {
+		from := p.Lines[places[index].line].Parts[places[index].part].Letters
+		to := chord.String()
+		if to != chords[index].String() {
+			res = append(res, ChordChange{LineNumber: p.Lines[places[index].line].LineNumber, Part: places[index].part, From: from, To: to})
+		}
+	}
+
+	return res, nil
+}
+
+// Reharmonize works out how the given reharmonization would change all of
+// the song's chords, going by the key in its metadata
+func (p ParsedContent) Reharmonize(technique string) ([]ChordChange, error) {
+	return p.ReharmonizeLines(technique, p.Metadata.Key, 0, len(p.Lines)-1)
+}
+
+// ApplyChordChanges returns the text of each line the changes are on, with
+// the chords changed and the rest of the line kept in its columns
+func (p ParsedContent) ApplyChordChanges(changes []ChordChange) (map[int]string, error) {
+	parts := make(map[int][]LetterRun)
+	for _, change := range changes {
+		if change.LineNumber < 0 || change.LineNumber >= len(p.Lines) {
+			return nil, fmt.Errorf("there is no line %d", change.LineNumber)
+		}
+
+		line := p.Lines[change.LineNumber]
+		if change.Part < 0 || change.Part >= len(line.Parts) || line.Parts[change.Part].Type != LetterRunTypes.CHORDRUN {
+			return nil, fmt.Errorf("part %d of line %d is not a chord", change.Part, change.LineNumber)
+		}
+
+		if _, found := parts[change.LineNumber]; !found {
+			parts[change.LineNumber] = slices.Clone(line.Parts)
+		}
+
+		ReplaceChord(parts[change.LineNumber], change.Part, change.To)
+	}
+
+	res := make(map[int]string)
+	for lineNumber, lineParts := range parts {
+		res[lineNumber] = ChordLineText(lineParts, p.Lines[lineNumber].Markup != "")
+	}
+
+	return res, nil
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+const reharmonizeSong = `[Verse]
+C      Am     Dm     G7
+Here is a song to sing
+F      C      G      Am
+Every word of it again
+Cmaj9  Bbmaj9#11/D  E7/G#
+And the end`
+
+func reharmonized(t *testing.T, technique string, key string) []string {
+	content := ParsedContent{}
+	err := content.ParseContent(reharmonizeSong)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := content.ReharmonizeLines(technique, key, 0, len(content.Lines)-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts, err := content.ApplyChordChanges(changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := make([]string, 0)
+	for _, index := range []int{1, 3, 5} {
+		text, found := texts[index]
+		if !found {
+			text = content.Lines[index].Text
+		}
+		res = append(res, text)
+	}
+
+	return res
+}
+
+func TestReharmonizations(t *testing.T) {
+	cases := []struct {
+		technique string
+		key       string
+		expected  []string
+	}{
+		{TritoneSubstitution, "", []string{"C      Am     Dm     Db7", "F      C      G      Am", "Cmaj9  Bbmaj9#11/D  Bb7/D"}},
+		{DiatonicSevenths, "C", []string{"Cmaj7  Am7    Dm7    G7", "Fmaj7  Cmaj7  G7     Am7", "Cmaj9  Bbmaj9#11/D  E7/G#"}},
+		{SimplifyToTriads, "", []string{"C      Am     Dm     G", "F      C      G      Am", "C      Bb/D         E/G#"}},
+		{RelativeSwap, "", []string{"Am     C      F      G7", "Dm     Am     Em     C", "Cmaj9  Bbmaj9#11/D  E7/G#"}},
+		{BassWalkDown, "", []string{"C      Am     Dm     G7", "F      C      G/B    Am", "Cmaj9  Bbmaj9#11/D  E7/G#"}},
+	}
+
+	for _, test := range cases {
+		res := reharmonized(t, test.technique, test.key)
+		if !reflect.DeepEqual(res, test.expected) {
+			t.Errorf("%s: Expected:\n'%#v'\ngot:\n'%#v'", test.technique, test.expected, res)
+		}
+	}
+}
+
+func TestDiatonicSevenths(t *testing.T) {
+	for key, expected := range map[string]string{
+		"C":  "Cmaj7  Am7    Dm7    G7",
+		"Am": "Cmaj7  Am7    Dm7    G7",
+		"":   "Cmaj7  Am7    Dm7    G7",
+		"F":  "C7     Am7    Dm7    G7",
+	} {
+		res := reharmonized(t, DiatonicSevenths, key)
+		if res[0] != expected {
+			t.Errorf("In %#v expected:\n'%#v'\ngot:\n'%#v'", key, expected, res[0])
+		}
+	}
+}
+
+func TestReharmonizeSelectedLines(t *testing.T) {
+	content := ParsedContent{}
+	err := content.ParseContent(reharmonizeSong)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := content.ReharmonizeLines(RelativeSwap, "", 3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []ChordChange{
+		{LineNumber: 3, Part: 0, From: "F", To: "Dm"},
+		{LineNumber: 3, Part: 2, From: "C", To: "Am"},
+		{LineNumber: 3, Part: 4, From: "G", To: "Em"},
+		{LineNumber: 3, Part: 6, From: "Am", To: "C"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, changes)
+	}
+
+	_, err = content.ReharmonizeLines("jazz", "", 0, 1)
+	if err == nil {
+		t.Errorf("Expected an unknown reharmonization to be an error")
+	}
+}
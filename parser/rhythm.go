@@ -0,0 +1,159 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var rhythmMarks = regexp.MustCompile(`^[./%|:]+$`)
+var repeatMark = regexp.MustCompile(`^(?i)(?:x(\d+)|(\d+)x)$`)
+
+// IsRhythmMark reports whether text on a chord line is a rhythm mark
+// rather than a chord
+func IsRhythmMark(text string) bool {
+	text = strings.Trim(text, "()")
+	return rhythmMarks.MatchString(text) || repeatMark.MatchString(text)
+}
+
+// markRhythm turns the runs which are rhythm marks, like bar lines, slashes,
+// dots and %, into separators, and repeat marks like (x2) into annotations,
+// so a line of chords with rhythm is still a line of chords
+func markRhythm(parts []LetterRun) []LetterRun {
+	res := make([]LetterRun, 0, len(parts))
+	for _, part := range parts {
+		switch {
+		case part.Type == LetterRunTypes.SEPARATORRUN || part.Type == LetterRunTypes.ANNOTATIONRUN:
+		case repeatMark.MatchString(strings.Trim(part.Letters, "()")):
+			part = makeAnnotationRun(part.Letters)
+		case IsRhythmMark(part.Letters):
+			part = LetterRun{Letters: part.Letters, OriginalLetters: part.Letters, Type: LetterRunTypes.SEPARATORRUN}
+		}
+
+		if part.Type == LetterRunTypes.SEPARATORRUN && len(res) > 0 && res[len(res)-1].Type == LetterRunTypes.SEPARATORRUN {
+			res[len(res)-1].Letters += part.Letters
+			res[len(res)-1].OriginalLetters = res[len(res)-1].Letters
+			continue
+		}
+
+		res = append(res, part)
+	}
+
+	return res
+}
+
+type rhythmBar struct {
+	chords []int
+	marked bool
+	repeat bool
+}
+
+// annotateRhythm sets how long each chord on a chord line lasts, and how
+// many times the line is played, if the line says
+func annotateRhythm(line *Line) {
+	for index := range line.Parts {
+		line.Parts[index].Beats = 0
+		line.Parts[index].Bars = 0
+		line.Parts[index].Push = false
+		line.Parts[index].Diamond = false
+	}
+	line.Repeat = 0
+
+	for index, part := range line.Parts {
+		if part.Type != LetterRunTypes.CHORDRUN || index == 0 || line.Parts[index-1].Type != LetterRunTypes.SEPARATORRUN {
+			continue
+		}
+
+		before := line.Parts[index-1].Letters
+		line.Parts[index].Push = strings.HasSuffix(before, "^")
+		line.Parts[index].Diamond = strings.HasSuffix(before, "<") && index+1 < len(line.Parts) &&
+			strings.HasPrefix(line.Parts[index+1].Letters, ">")
+	}
+
+	bars := []rhythmBar{{}}
+	hasBarLines := false
+	hasMarks := false
+	last := -1
+	for index, part := range line.Parts {
+		if part.Type == LetterRunTypes.CHORDRUN {
+			bars[len(bars)-1].chords = append(bars[len(bars)-1].chords, index)
+			line.Parts[index].Beats = 1
+			last = index
+			continue
+		}
+
+		if part.Type == LetterRunTypes.ANNOTATIONRUN {
+			for _, word := range strings.FieldsFunc(part.Letters, func(ch rune) bool { return ch == ' ' || ch == '(' || ch == ')' }) {
+				match := repeatMark.FindStringSubmatch(word)
+				if match != nil {
+					count, _ := strconv.Atoi(match[1] + match[2])
+					line.Repeat = count
+				}
+			}
+			continue
+		}
+
+		if part.Type != LetterRunTypes.SEPARATORRUN {
+			continue
+		}
+
+		for _, word := range strings.Fields(part.Letters) {
+			for _, ch := range word {
+				switch ch {
+				case '|':
+					hasBarLines = true
+					bars = append(bars, rhythmBar{})
+				case '%':
+					hasBarLines = true
+					current := &bars[len(bars)-1]
+					if len(current.chords) > 0 || current.repeat {
+						bars = append(bars, rhythmBar{repeat: true})
+					} else {
+						current.repeat = true
+					}
+				case '/', '.':
+					hasMarks = true
+					bars[len(bars)-1].marked = true
+					if last >= 0 {
+						line.Parts[last].Beats += 1
+					}
+				}
+			}
+		}
+	}
+
+	if !hasBarLines && !hasMarks {
+		for index := range line.Parts {
+			line.Parts[index].Beats = 0
+		}
+		return
+	}
+
+	last = -1
+	for index, bar := range bars {
+		if len(bar.chords) == 0 {
+			// The first and last bars are what's before the first bar line and
+			// after the last one, which are only bars if there's something in them
+			interior := index > 0 && index < len(bars)-1
+			if !bar.marked && last >= 0 && (interior || bar.repeat) {
+				line.Parts[last].Bars += 1
+			}
+			continue
+		}
+
+		if !bar.marked && hasBarLines {
+			for _, chord := range bar.chords {
+				line.Parts[chord].Beats = 0
+				line.Parts[chord].Bars = 1 / float64(len(bar.chords))
+			}
+		}
+
+		last = bar.chords[len(bar.chords)-1]
+	}
+}
+
+// Duration returns the number of beats the chord lasts, given the number
+// of beats in a bar, or 0 if the chart doesn't say
+func (run LetterRun) Duration(beatsPerBar int) float64 {
+	return run.Beats + run.Bars*float64(beatsPerBar)
+}
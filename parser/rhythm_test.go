@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/samber/lo"
+)
+
+func chordDurations(t *testing.T, text string) ([][3]any, int) {
+	t.Helper()
+	content := ParsedContent{}
+	err := content.ParseContent(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := content.Lines[0]
+	if line.Type != LineTypes.CHORDS {
+		t.Fatalf("Expected %#v to be a chord line, got %v", text, line.Type)
+	}
+
+	chords := lo.Filter(line.Parts, func(part LetterRun, _ int) bool {
+		return part.Type == LetterRunTypes.CHORDRUN
+	})
+
+	return lo.Map(chords, func(part LetterRun, _ int) [3]any {
+		return [3]any{part.Letters, part.Beats, part.Bars}
+	}), line.Repeat
+}
+
+func TestRhythmAnnotations(t *testing.T) {
+	cases := []struct {
+		text     string
+		expected [][3]any
+		repeat   int
+	}{
+		{"C  G  Am  F", [][3]any{{"C", 0.0, 0.0}, {"G", 0.0, 0.0}, {"Am", 0.0, 0.0}, {"F", 0.0, 0.0}}, 0},
+		{"C | G/B | Am | F", [][3]any{{"C", 0.0, 1.0}, {"G/B", 0.0, 1.0}, {"Am", 0.0, 1.0}, {"F", 0.0, 1.0}}, 0},
+		{"| C G | Am |", [][3]any{{"C", 0.0, 0.5}, {"G", 0.0, 0.5}, {"Am", 0.0, 1.0}}, 0},
+		{"C / / / G / / /", [][3]any{{"C", 4.0, 0.0}, {"G", 4.0, 0.0}}, 0},
+		{"C . . G . .", [][3]any{{"C", 3.0, 0.0}, {"G", 3.0, 0.0}}, 0},
+		{"| C / G/B / | F |", [][3]any{{"C", 2.0, 0.0}, {"G/B", 2.0, 0.0}, {"F", 0.0, 1.0}}, 0},
+		{"| C | | G | % |", [][3]any{{"C", 0.0, 2.0}, {"G", 0.0, 2.0}}, 0},
+		{"C  G  (x2)", [][3]any{{"C", 0.0, 0.0}, {"G", 0.0, 0.0}}, 2},
+		{"| Am | F | 3x", [][3]any{{"Am", 0.0, 1.0}, {"F", 0.0, 1.0}}, 3},
+	}
+
+	for _, c := range cases {
+		got, repeat := chordDurations(t, c.text)
+		if !reflect.DeepEqual(got, c.expected) || repeat != c.repeat {
+			t.Errorf("For %#v expected:\n'%#v' x%d\ngot:\n'%#v' x%d", c.text, c.expected, c.repeat, got, repeat)
+		}
+	}
+}
+
+func TestRhythmSurvivesTransposition(t *testing.T) {
+	content := ParsedContent{}
+	err := content.ParseContent("| C . . . | Bb |\nWords go here\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content.Transpose(2)
+	if content.Lines[0].String() != "| D . . . | C  |" {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", "| D . . . | C  |", content.Lines[0].String())
+	}
+
+	content.Metadata.TimeSignature = "3/4"
+	beats := lo.Map(content.Progression(), func(chord ProgressionChord, _ int) float64 {
+		return chord.Beats
+	})
+	if !reflect.DeepEqual(beats, []float64{4, 3}) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", []float64{4, 3}, beats)
+	}
+}
+
+func TestProgressionRepeatsLines(t *testing.T) {
+	content := ParsedContent{}
+	err := content.ParseContent("C  G (x2)\nOh yeah\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts := lo.Map(content.Progression(), func(chord ProgressionChord, _ int) [2]string {
+		return [2]string{chord.Text, chord.Lyric}
+	})
+	expected := [][2]string{{"C", "Oh"}, {"G", "yeah"}, {"C", ""}, {"G", ""}}
+	if !reflect.DeepEqual(texts, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, texts)
+	}
+}
+
+func TestPushAndDiamond(t *testing.T) {
+	content := ParsedContent{}
+	err := content.ParseContent("| <C> | ^G  F |\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	marks := lo.Map(content.Progression(), func(chord ProgressionChord, _ int) [3]any {
+		return [3]any{chord.Text, chord.Push, chord.Diamond}
+	})
+	expected := [][3]any{{"C", false, true}, {"G", true, false}, {"F", false, false}}
+	if !reflect.DeepEqual(marks, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, marks)
+	}
+}
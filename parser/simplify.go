@@ -0,0 +1,237 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	PowerChordLevel = "power"
+	TriadLevel      = "triads"
+	SeventhLevel    = "sevenths"
+)
+
+// SimplificationLevels are the levels chords can be simplified to, simplest first
+var SimplificationLevels = []string{PowerChordLevel, TriadLevel, SeventhLevel}
+
+// Simplification says how a song's chords are shown simplified, without
+// changing the song
+type Simplification struct {
+	Level    string
+	DropBass bool
+	Capo     bool
+}
+
+// Validate returns an error if the level isn't one of SimplificationLevels
+func (s Simplification) Validate() error {
+	if s.Level != "" && !slices.Contains(SimplificationLevels, s.Level) {
+		return fmt.Errorf("%#v is not a level chords can be simplified to", s.Level)
+	}
+
+	return nil
+}
+
+// openChords are the chords a guitar in standard tuning plays in the first
+// position without a barre
+const openChords = "A Am A7 Am7 Amaj7 Asus2 Asus4 C C7 Cmaj7 Cadd9 D Dm D7 Dm7 Dmaj7 Dsus2 Dsus4 " +
+	"E Em E7 Em7 Emaj7 Esus4 Fmaj7 G G7 B7"
+
+var openChordShapes map[string]bool
+
+func init() {
+	openChordShapes = make(map[string]bool)
+	for _, name := range strings.Fields(openChords) {
+		openChordShapes[MakeChord(name).shape()] = true
+	}
+}
+
+// shape returns the chord's root and suffix in a form which is the same
+// however the chord is spelled
+func (c Chord) shape() string {
+	return strconv.Itoa(c.Root()) + chordSuffix(c.Flavor)
+}
+
+// NeedsBarre reports whether playing the chord on a guitar in standard
+// tuning needs a barre, going by the open shapes which don't. The bass note
+// is left out, and power chords never need one.
+func (c Chord) NeedsBarre() bool {
+	if c.Note == "" || chordSuffix(c.Flavor) == "5" {
+		return false
+	}
+
+	return !openChordShapes[c.shape()]
+}
+
+// PowerChord returns the chord's root and fifth, keeping its bass note
+func (c Chord) PowerChord() Chord {
+	if c.Note == "" || chordSuffix(c.Flavor) == "5" {
+		return c
+	}
+
+	c.Flavor = "5"
+
+	return c
+}
+
+// triadSevenths are the suffixes of the chords made of a triad and a seventh,
+// by the triad's suffix and the seventh's half steps above the root
+var triadSevenths = map[string]map[int]string{
+	"":     {10: "7", 11: "maj7"},
+	"m":    {10: "m7", 11: "mmaj7"},
+	"dim":  {9: "dim7", 10: "m7b5"},
+	"aug":  {10: "7#5", 11: "maj7#5"},
+	"sus4": {10: "7sus4"},
+}
+
+// Seventh returns the chord stripped down to its triad and its seventh, if
+// it has one, keeping its bass note. Chords which are already that, or whose
+// suffix isn't known, are returned as they are.
+func (c Chord) Seventh() Chord {
+	triad := c.Triad()
+	if triad.Flavor == c.Flavor {
+		return c
+	}
+
+	intervals := c.Quality().Intervals
+	for _, seventh := range []int{10, 11, 9} {
+		flavor, found := triadSevenths[triad.Flavor][seventh]
+		if !found || !slices.Contains(intervals, seventh) {
+			continue
+		}
+
+		if chordSuffix(c.Flavor) == flavor {
+			return c
+		}
+
+		triad.Flavor = flavor
+		break
+	}
+
+	return triad
+}
+
+// simplified returns the chord at the given level of simplification
+func (c Chord) simplified(options Simplification) Chord {
+	switch options.Level {
+	case PowerChordLevel:
+		c = c.PowerChord()
+	case TriadLevel:
+		c = c.Triad()
+	case SeventhLevel:
+		c = c.Seventh()
+	}
+
+	if options.DropBass {
+		c.BassNote = nil
+	}
+
+	return c
+}
+
+// SuggestCapo returns the fret a capo can go on so the chords can be played
+// with the fewest barre chords, and 0 if a capo doesn't help. Only the first
+// seven frets are tried, and the lowest of those which do as well is used.
+func SuggestCapo(chords []Chord) int {
+	barres := func(fret int) int {
+		res := 0
+		for _, chord := range chords {
+			// The bass note is shared with the chord it came from
+			chord.BassNote = nil
+			chord.Transpose(-fret)
+			if chord.NeedsBarre() {
+				res += 1
+			}
+		}
+
+		return res
+	}
+
+	res, fewest := 0, barres(0)
+	for fret := 1; fret <= 7 && fewest > 0; fret++ {
+		count := barres(fret)
+		if count < fewest {
+			res, fewest = fret, count
+		}
+	}
+
+	return res
+}
+
+// showChord shows the run as the given letters instead of the chord in it,
+// taking up or giving back space after it so the chords after it stay in
+// their columns
+func (line *Line) showChord(index int, letters string) {
+	part := &line.Parts[index]
+	width := DisplayWidth(part.Letters)
+	if part.TransposedLetters != "" {
+		width = DisplayWidth(part.TransposedLetters)
+	}
+
+	if index < len(line.Parts)-1 {
+		letters = PadToWidth(letters, width)
+	}
+
+	extra := DisplayWidth(letters) - width
+	if extra > 0 && index < len(line.Parts)-1 && line.Parts[index+1].Type == LetterRunTypes.SEPARATORRUN {
+		next := line.Parts[index+1].Letters
+		spaces := len(next) - len(strings.TrimLeft(next, " "))
+		remove := min(extra, spaces-1)
+		if remove > 0 {
+			line.Parts[index+1].Letters = next[remove:]
+		}
+	}
+
+	part.TransposedLetters = letters
+}
+
+// Simplify shows the content's chords simplified as the options say. It is
+// done to the chords as they are shown, so after any transposition, and it
+// returns the fret a capo goes on, or 0 for none, which is also put in the
+// metadata.
+func (p *ParsedContent) Simplify(options Simplification) int {
+	chords := make([]Chord, 0)
+	for lineIndex := range p.Lines {
+		if p.Lines[lineIndex].Type != LineTypes.CHORDS {
+			continue
+		}
+
+		for _, part := range p.Lines[lineIndex].Parts {
+			if part.Type == LetterRunTypes.CHORDRUN && part.Chord.Note != "" {
+				chords = append(chords, part.Chord.simplified(options))
+			}
+		}
+	}
+
+	capo := 0
+	if options.Capo {
+		capo = SuggestCapo(chords)
+	}
+
+	for lineIndex := range p.Lines {
+		line := &p.Lines[lineIndex]
+		if line.Type != LineTypes.CHORDS {
+			continue
+		}
+
+		for partIndex := range line.Parts {
+			part := &line.Parts[partIndex]
+			if part.Type != LetterRunTypes.CHORDRUN || part.Chord.Note == "" {
+				continue
+			}
+
+			part.Chord = part.Chord.simplified(options)
+			part.Chord.Transpose(-capo)
+			if part.Chord.String() != part.ShownLetters() {
+				line.showChord(partIndex, part.Chord.String())
+			}
+		}
+	}
+
+	if capo > 0 {
+		p.Metadata.Capo = strconv.Itoa(capo)
+	}
+
+	return capo
+}
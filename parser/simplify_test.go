@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+const beginnerSong = `[Verse]
+Bbmaj9#11/D  Gm7  Ebmaj7  F7sus4
+Words to sing along with`
+
+func simplifiedLines(t *testing.T, options Simplification) ([]string, int) {
+	content := ParsedContent{}
+	err := content.ParseContent(beginnerSong)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	capo := content.Simplify(options)
+
+	return []string{content.Lines[1].String(), content.Lines[2].String()}, capo
+}
+
+func TestSimplify(t *testing.T) {
+	cases := []struct {
+		options  Simplification
+		expected string
+		capo     int
+	}{
+		{Simplification{Level: PowerChordLevel}, "Bb5/D        G5   Eb5     F5", 0},
+		{Simplification{Level: TriadLevel}, "Bb/D         Gm   Eb      Fsus4", 0},
+		{Simplification{Level: SeventhLevel}, "Bbmaj7/D     Gm7  Ebmaj7  F7sus4", 0},
+		{Simplification{Level: TriadLevel, DropBass: true}, "Bb           Gm   Eb      Fsus4", 0},
+		{Simplification{Level: TriadLevel, DropBass: true, Capo: true}, "G            Em   C       Dsus4", 3},
+	}
+
+	for _, test := range cases {
+		res, capo := simplifiedLines(t, test.options)
+		expected := []string{test.expected, "Words to sing along with"}
+		if !reflect.DeepEqual(res, expected) || capo != test.capo {
+			t.Errorf("%#v: Expected:\n'%#v' with capo %d\ngot:\n'%#v' with capo %d", test.options, expected, test.capo, res, capo)
+		}
+	}
+}
+
+func TestSimplifyKeepsTheSong(t *testing.T) {
+	content := ParsedContent{}
+	err := content.ParseContent(beginnerSong)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content.Transpose(2)
+	content.Simplify(Simplification{Level: TriadLevel})
+	if content.Lines[1].String() != "C/E          Am   F       Gsus4" {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", "C/E          Am   F       Gsus4", content.Lines[1].String())
+	}
+
+	if content.Lines[1].Parts[0].Letters != "Bbmaj9#11/D" {
+		t.Errorf("Expected the chord as written to be kept, got %#v", content.Lines[1].Parts[0].Letters)
+	}
+}
+
+func TestNeedsBarre(t *testing.T) {
+	for name, expected := range map[string]bool{"C": false, "Em7": false, "G/B": false, "F": true, "Bm": true, "Bb5": false, "A#m": true} {
+		if MakeChord(name).NeedsBarre() != expected {
+			t.Errorf("Expected %s needing a barre to be %v", name, expected)
+		}
+	}
+}
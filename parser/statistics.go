@@ -0,0 +1,280 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// ProgressionLength is the number of chords in the progressions a song's
+// statistics count
+const ProgressionLength = 4
+
+// mostCommonProgressions is the number of progressions a song's statistics
+// list
+const mostCommonProgressions = 5
+
+// ChordCount is a chord and the number of times it is played
+type ChordCount struct {
+	Chord string
+	Count int
+}
+
+// ProgressionCount is a run of chords played more than once, as Nashville
+// numbers in the song's key, like 1-5-6-4, and as the chords it was first
+// played with, along with the number of times it is played
+type ProgressionCount struct {
+	Numbers string
+	Chords  string
+	Count   int
+}
+
+// Statistics sums up a song for planning a set. The chords are counted as
+// they are played, following the song's flow if it has one, and as they are
+// shown, so after any transposition or simplification. Key is the key in
+// the metadata, or the one the chords suggest if it has none, and Keys are
+// the keys the sections suggest, in the order they first come.
+type Statistics struct {
+	Key            string
+	Keys           []string
+	DistinctChords []string
+	ChordFrequency []ChordCount
+	Progressions   []ProgressionCount
+	BarreChords    []string
+	Sections       int
+	Lines          int
+	ChordLines     int
+	LyricLines     int
+	Bars           int
+}
+
+// keyNames are the usual names of the major and minor keys on each pitch
+var keyNames = []string{"C", "Db", "D", "Eb", "E", "F", "F#", "G", "Ab", "A", "Bb", "B"}
+var minorKeyNames = []string{"Cm", "C#m", "Dm", "Ebm", "Em", "Fm", "F#m", "Gm", "G#m", "Am", "Bbm", "Bm"}
+
+// harmonicMinorScale is the scale a minor key's chords are taken from, with
+// the raised seventh its dominant chord has
+var harmonicMinorScale = []int{0, 2, 3, 5, 7, 8, 11}
+
+// keyScore says how well the chords fit the key with the given tonic: each
+// of their notes in the key's scale counts for it, and those outside count
+// against it, with the tonic chord itself, and ending on it, counting most
+func keyScore(chords []Chord, tonic int, minor bool) int {
+	scale, tonicFlavor := majorScale, ""
+	if minor {
+		scale, tonicFlavor = harmonicMinorScale, "m"
+	}
+
+	res := 0
+	for _, chord := range chords {
+		for _, tone := range chord.Tones() {
+			if slices.Contains(scale, (tone-tonic+12)%12) {
+				res += 1
+			} else {
+				res -= 2
+			}
+		}
+
+		if chord.Root() == tonic && chordSuffix(chord.Triad().Flavor) == tonicFlavor {
+			res += 3
+		}
+	}
+
+	last := chords[len(chords)-1]
+	if last.Root() == tonic && chordSuffix(last.Triad().Flavor) == tonicFlavor {
+		res += 3
+	}
+
+	return res
+}
+
+// EstimateKey returns the key the chords fit best, or "" if there are none.
+// Major keys are preferred to their relative minors unless the chords lean
+// on the minor tonic.
+func EstimateKey(chords []Chord) string {
+	chords = slices.DeleteFunc(slices.Clone(chords), func(chord Chord) bool { return chord.Root() < 0 })
+	if len(chords) == 0 {
+		return ""
+	}
+
+	res, best := "", math.MinInt
+	for tonic := range 12 {
+		for _, minor := range []bool{false, true} {
+			score := keyScore(chords, tonic, minor)
+			if score <= best {
+				continue
+			}
+
+			res, best = keyNames[tonic], score
+			if minor {
+				res = minorKeyNames[tonic]
+			}
+		}
+	}
+
+	return res
+}
+
+// progressionCounts counts each run of the given number of chords, leaving
+// out chords which are the same as the one before, and returns the runs
+// played more than once, most often played first
+func progressionCounts(chords []Chord, key Chord, length int) []ProgressionCount {
+	changes := make([]Chord, 0, len(chords))
+	for _, chord := range chords {
+		if len(changes) == 0 || changes[len(changes)-1].String() != chord.String() {
+			changes = append(changes, chord)
+		}
+	}
+
+	res := make([]ProgressionCount, 0)
+	found := make(map[string]int)
+	for start := 0; start+length <= len(changes); start++ {
+		numbers := make([]string, length)
+		names := make([]string, length)
+		for index, chord := range changes[start : start+length] {
+			numbers[index] = chord.String()
+			if key.Root() >= 0 {
+				numbers[index] = nashvilleDegree(chord, key)
+			}
+			names[index] = chord.String()
+		}
+
+		progression := strings.Join(numbers, "-")
+		if index, seen := found[progression]; seen {
+			res[index].Count += 1
+			continue
+		}
+
+		found[progression] = len(res)
+		res = append(res, ProgressionCount{Numbers: progression, Chords: strings.Join(names, " "), Count: 1})
+	}
+
+	res = slices.DeleteFunc(res, func(count ProgressionCount) bool { return count.Count < 2 })
+	slices.SortStableFunc(res, func(a ProgressionCount, b ProgressionCount) int { return b.Count - a.Count })
+
+	return res[:min(len(res), mostCommonProgressions)]
+}
+
+// Statistics works out the song's statistics
+func (p ParsedContent) Statistics() Statistics {
+	res := Statistics{
+		Keys:           make([]string, 0),
+		DistinctChords: make([]string, 0),
+		ChordFrequency: make([]ChordCount, 0),
+		BarreChords:    make([]string, 0),
+	}
+
+	for _, line := range p.Lines {
+		if strings.TrimSpace(line.Text) != "" {
+			res.Lines += 1
+		}
+
+		switch line.Type {
+		case LineTypes.SECTION:
+			res.Sections += 1
+		case LineTypes.CHORDS:
+			res.ChordLines += 1
+		case LineTypes.LYRICS:
+			res.LyricLines += 1
+		}
+	}
+
+	// A chord lasts as long as the chart says, or a bar if it doesn't, and
+	// each section starts on a new bar, as in a chord grid
+	beatsPerBar, _ := p.Metadata.Meter()
+	beats := 0.0
+	chords := make([]Chord, 0)
+	sections := make([][]Chord, 0)
+	counts := make(map[string]int)
+	for _, played := range p.Arrangement() {
+		chord := played.Chord
+		if chord.Root() < 0 {
+			continue
+		}
+
+		if len(sections) == 0 || played.StartsSection {
+			res.Bars += int(math.Ceil(beats/float64(beatsPerBar) - 1e-9))
+			beats = 0
+			sections = append(sections, make([]Chord, 0))
+		}
+
+		if played.Beats > 0 {
+			beats += played.Beats
+		} else {
+			beats += float64(beatsPerBar)
+		}
+
+		chords = append(chords, chord)
+		sections[len(sections)-1] = append(sections[len(sections)-1], chord)
+
+		name := chord.String()
+		if _, seen := counts[name]; !seen {
+			res.DistinctChords = append(res.DistinctChords, name)
+			if chord.NeedsBarre() {
+				res.BarreChords = append(res.BarreChords, name)
+			}
+		}
+		counts[name] += 1
+	}
+	res.Bars += int(math.Ceil(beats/float64(beatsPerBar) - 1e-9))
+
+	for _, name := range res.DistinctChords {
+		res.ChordFrequency = append(res.ChordFrequency, ChordCount{Chord: name, Count: counts[name]})
+	}
+	slices.SortStableFunc(res.ChordFrequency, func(a ChordCount, b ChordCount) int { return b.Count - a.Count })
+
+	res.Key = p.Metadata.Key
+	if MakeChord(res.Key).Root() < 0 {
+		res.Key = EstimateKey(chords)
+	}
+
+	for _, section := range sections {
+		key := EstimateKey(section)
+		if key != "" && !slices.Contains(res.Keys, key) {
+			res.Keys = append(res.Keys, key)
+		}
+	}
+
+	res.Progressions = progressionCounts(chords, MakeChord(res.Key), ProgressionLength)
+
+	return res
+}
+
+// String sets out the statistics as a short report
+func (s Statistics) String() string {
+	res := strings.Builder{}
+	line := func(label string, value string) {
+		if value != "" {
+			fmt.Fprintf(&res, "%-16s%s\n", label+":", value)
+		}
+	}
+
+	line("Key", s.Key)
+	line("Keys touched", strings.Join(s.Keys, ", "))
+	line("Sections", fmt.Sprint(s.Sections))
+	line("Lines", fmt.Sprintf("%d (%d chords, %d lyrics)", s.Lines, s.ChordLines, s.LyricLines))
+	line("Length", fmt.Sprintf("about %d bars", s.Bars))
+	line("Chords", strings.Join(s.DistinctChords, " "))
+
+	frequency := make([]string, len(s.ChordFrequency))
+	for index, count := range s.ChordFrequency {
+		frequency[index] = fmt.Sprintf("%s ×%d", count.Chord, count.Count)
+	}
+	line("Played", strings.Join(frequency, ", "))
+
+	barres := "none"
+	if len(s.BarreChords) > 0 {
+		barres = strings.Join(s.BarreChords, " ")
+	}
+	line("Barre chords", barres)
+
+	if len(s.Progressions) > 0 {
+		res.WriteString("Progressions:\n")
+		for _, progression := range s.Progressions {
+			fmt.Fprintf(&res, "  %s (%s) ×%d\n", progression.Numbers, progression.Chords, progression.Count)
+		}
+	}
+
+	return res.String()
+}
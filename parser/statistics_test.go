@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+const setSong = `[Verse]
+C       G       Am      F
+These are the lyrics to sing
+C       G       Am      F
+More of the lyrics to sing
+
+[Chorus]
+F       G       C       Am
+Sing along with the chorus
+F       G       C
+Sing it once again
+
+[Bridge]
+D       A       Bm      G
+Somewhere else entirely now`
+
+func TestStatistics(t *testing.T) {
+	content := ParsedContent{}
+	err := content.ParseContent(setSong)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Statistics{
+		Key:            "C",
+		Keys:           []string{"C", "D"},
+		DistinctChords: []string{"C", "G", "Am", "F", "D", "A", "Bm"},
+		ChordFrequency: []ChordCount{{"G", 5}, {"C", 4}, {"F", 4}, {"Am", 3}, {"D", 1}, {"A", 1}, {"Bm", 1}},
+		Progressions:   []ProgressionCount{{"1-5-6-4", "C G Am F", 2}, {"6-4-5-1", "Am F G C", 2}},
+		BarreChords:    []string{"F", "Bm"},
+		Sections:       3,
+		Lines:          13,
+		ChordLines:     5,
+		LyricLines:     5,
+		Bars:           19,
+	}
+
+	res := content.Statistics()
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, res)
+	}
+}
+
+func TestStatisticsFollowTheShownChords(t *testing.T) {
+	content := ParsedContent{Metadata: Metadata{TimeSignature: "3/4"}}
+	err := content.ParseContent("| Am . . | Dm . . | E7 . . | Am . . |")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content.Transpose(2)
+	res := content.Statistics()
+	if res.Key != "Bm" || res.Bars != 4 || !reflect.DeepEqual(res.BarreChords, []string{"Bm", "F#7"}) {
+		t.Errorf("Expected Bm over 4 bars with barres on Bm and F#7, got:\n'%#v'", res)
+	}
+}
+
+func TestEstimateKey(t *testing.T) {
+	cases := map[string][]string{
+		"G":  {"G", "C", "D", "G"},
+		"Am": {"Am", "Dm", "E7", "Am"},
+		"Eb": {"Eb", "Ab", "Bb7", "Eb"},
+		"":   {},
+	}
+
+	for expected, names := range cases {
+		chords := make([]Chord, len(names))
+		for index, name := range names {
+			chords[index] = MakeChord(name)
+		}
+
+		res := EstimateKey(chords)
+		if res != expected {
+			t.Errorf("%v: Expected:\n'%#v'\ngot:\n'%#v'", names, expected, res)
+		}
+	}
+}
+
+func TestStatisticsFollowAFlowWithRepeatedSections(t *testing.T) {
+	content := ParsedContent{Metadata: Metadata{Flow: []string{"V", "C", "V", "C"}}}
+	err := content.ParseContent("[Verse]\nC  G\n[Chorus]\nF  G\n[Verse]\nC  G\n[Chorus]\nF  C\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := content.Statistics()
+	expected := []ChordCount{{"C", 3}, {"G", 3}, {"F", 2}}
+	if res.Bars != 8 || !reflect.DeepEqual(res.ChordFrequency, expected) {
+		t.Errorf("Expected 8 bars and:\n'%#v'\ngot %d bars and:\n'%#v'", expected, res.Bars, res.ChordFrequency)
+	}
+}
@@ -0,0 +1,206 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Degree is a note added to, altered in, or taken out of a chord's basic
+// kind, numbered from the root as in "add9" or "b5"
+type Degree struct {
+	Value int
+	Alter int
+	Type  string
+}
+
+// ChordQuality describes what kind of chord a suffix makes. The kind is
+// one of MusicXML's kind values, and the intervals are the chord tones in
+// half steps above the root.
+type ChordQuality struct {
+	Kind      string
+	Intervals []int
+	Degrees   []Degree
+}
+
+var chordQualities = map[string]ChordQuality{
+	"":         {Kind: "major", Intervals: []int{0, 4, 7}},
+	"m":        {Kind: "minor", Intervals: []int{0, 3, 7}},
+	"5":        {Kind: "power", Intervals: []int{0, 7}},
+	"dim":      {Kind: "diminished", Intervals: []int{0, 3, 6}},
+	"dim7":     {Kind: "diminished-seventh", Intervals: []int{0, 3, 6, 9}},
+	"aug":      {Kind: "augmented", Intervals: []int{0, 4, 8}},
+	"sus":      {Kind: "suspended-fourth", Intervals: []int{0, 5, 7}},
+	"sus2":     {Kind: "suspended-second", Intervals: []int{0, 2, 7}},
+	"sus4":     {Kind: "suspended-fourth", Intervals: []int{0, 5, 7}},
+	"6":        {Kind: "major-sixth", Intervals: []int{0, 4, 7, 9}},
+	"7":        {Kind: "dominant", Intervals: []int{0, 4, 7, 10}},
+	"maj7":     {Kind: "major-seventh", Intervals: []int{0, 4, 7, 11}},
+	"m7":       {Kind: "minor-seventh", Intervals: []int{0, 3, 7, 10}},
+	"7sus4":    {Kind: "suspended-fourth", Intervals: []int{0, 5, 7, 10}, Degrees: []Degree{{7, -1, "add"}}},
+	"maj9":     {Kind: "major-ninth", Intervals: []int{0, 4, 7, 11, 14}},
+	"maj11":    {Kind: "major-11th", Intervals: []int{0, 4, 7, 11, 14, 17}},
+	"maj13":    {Kind: "major-13th", Intervals: []int{0, 4, 7, 11, 14, 21}},
+	"maj9#11":  {Kind: "major-ninth", Intervals: []int{0, 4, 7, 11, 14, 18}, Degrees: []Degree{{11, 1, "add"}}},
+	"maj13#11": {Kind: "major-13th", Intervals: []int{0, 4, 7, 11, 14, 18, 21}, Degrees: []Degree{{11, 1, "add"}}},
+	"add9":     {Kind: "major", Intervals: []int{0, 4, 7, 14}, Degrees: []Degree{{9, 0, "add"}}},
+	"6add9":    {Kind: "major-sixth", Intervals: []int{0, 4, 7, 9, 14}, Degrees: []Degree{{9, 0, "add"}}},
+	"maj7b5":   {Kind: "major-seventh", Intervals: []int{0, 4, 6, 11}, Degrees: []Degree{{5, -1, "alter"}}},
+	"maj7#5":   {Kind: "major-seventh", Intervals: []int{0, 4, 8, 11}, Degrees: []Degree{{5, 1, "alter"}}},
+	"m6":       {Kind: "minor-sixth", Intervals: []int{0, 3, 7, 9}},
+	"m9":       {Kind: "minor-ninth", Intervals: []int{0, 3, 7, 10, 14}},
+	"m11":      {Kind: "minor-11th", Intervals: []int{0, 3, 7, 10, 14, 17}},
+	"m13":      {Kind: "minor-13th", Intervals: []int{0, 3, 7, 10, 14, 21}},
+	"madd9":    {Kind: "minor", Intervals: []int{0, 3, 7, 14}, Degrees: []Degree{{9, 0, "add"}}},
+	"m6add9":   {Kind: "minor-sixth", Intervals: []int{0, 3, 7, 9, 14}, Degrees: []Degree{{9, 0, "add"}}},
+	"mmaj7":    {Kind: "major-minor", Intervals: []int{0, 3, 7, 11}},
+	"mmaj9":    {Kind: "major-minor", Intervals: []int{0, 3, 7, 11, 14}, Degrees: []Degree{{9, 0, "add"}}},
+	"m7b5":     {Kind: "half-diminished", Intervals: []int{0, 3, 6, 10}},
+	"m7#5":     {Kind: "minor-seventh", Intervals: []int{0, 3, 8, 10}, Degrees: []Degree{{5, 1, "alter"}}},
+	"9":        {Kind: "dominant-ninth", Intervals: []int{0, 4, 7, 10, 14}},
+	"11":       {Kind: "dominant-11th", Intervals: []int{0, 4, 7, 10, 14, 17}},
+	"13":       {Kind: "dominant-13th", Intervals: []int{0, 4, 7, 10, 14, 21}},
+	"7b5":      {Kind: "dominant", Intervals: []int{0, 4, 6, 10}, Degrees: []Degree{{5, -1, "alter"}}},
+	"7#5":      {Kind: "augmented-seventh", Intervals: []int{0, 4, 8, 10}},
+	"7b9":      {Kind: "dominant", Intervals: []int{0, 4, 7, 10, 13}, Degrees: []Degree{{9, -1, "add"}}},
+}
+
+var noteSemitones = map[string]int{"C": 0, "D": 2, "E": 4, "F": 5, "G": 7, "A": 9, "B": 11}
+
+// Alter returns the half steps the accidental moves the note by
+func (c Chord) Alter() int {
+	switch c.Accidental {
+	case AccidentalTypes.SHARP:
+		return 1
+	case AccidentalTypes.FLAT:
+		return -1
+	}
+
+	return 0
+}
+
+// Root returns the pitch class of the chord's root, from 0 for C up to 11
+// for B, or -1 if the chord has no root
+func (c Chord) Root() int {
+	semitone, found := noteSemitones[c.Note]
+	if !found {
+		return -1
+	}
+
+	return (semitone + c.Alter() + 12) % 12
+}
+
+// Bass returns the pitch class of the chord's lowest note, which is the
+// root unless it is a slash chord
+func (c Chord) Bass() int {
+	if c.BassNote != nil && c.BassNote.Note != "" {
+		return c.BassNote.Root()
+	}
+
+	return c.Root()
+}
+
+// Quality returns what kind of chord this is. A suffix which isn't known,
+// as a tagged chord may have, is reported as MusicXML's "other", played as
+// a major triad.
+func (c Chord) Quality() ChordQuality {
+	quality, found := chordQualities[chordSuffix(strings.ToLower(c.Flavor))]
+	if !found {
+		return ChordQuality{Kind: "other", Intervals: chordQualities[""].Intervals}
+	}
+
+	return quality
+}
+
+// Tones returns the pitch classes of the notes in the chord, root first
+func (c Chord) Tones() []int {
+	root := c.Root()
+	if root < 0 {
+		return []int{}
+	}
+
+	intervals := c.Quality().Intervals
+	res := make([]int, len(intervals))
+	for index, interval := range intervals {
+		res[index] = (root + interval) % 12
+	}
+
+	return res
+}
+
+// Meter returns the number of beats in a bar and the note value of a beat,
+// going by the time signature, which is 4/4 if it isn't given or understood
+func (m Metadata) Meter() (int, int) {
+	top, bottom, found := strings.Cut(strings.TrimSpace(m.TimeSignature), "/")
+	if !found {
+		return 4, 4
+	}
+
+	beats, err := strconv.Atoi(strings.TrimSpace(top))
+	if err != nil || beats < 1 || beats > 32 {
+		return 4, 4
+	}
+
+	beatType, err := strconv.Atoi(strings.TrimSpace(bottom))
+	if err != nil || beatType < 1 || beatType > 32 || beatType&(beatType-1) != 0 {
+		return 4, 4
+	}
+
+	return beats, beatType
+}
+
+// BPM returns the tempo in beats per minute, or 0 if it isn't given or
+// understood
+func (m Metadata) BPM() int {
+	tempo := strings.TrimSpace(m.Tempo)
+	digits := tempo[:len(tempo)-len(strings.TrimLeft(tempo, "0123456789"))]
+	bpm, err := strconv.Atoi(digits)
+	if err != nil || bpm < 20 || bpm > 400 {
+		return 0
+	}
+
+	return bpm
+}
+
+var noteLetters = "CDEFGAB"
+var majorScale = []int{0, 2, 4, 5, 7, 9, 11}
+var chromaticNumbers = []string{"1", "b2", "2", "b3", "3", "4", "b5", "5", "b6", "6", "b7", "7"}
+
+// nashvilleDegree returns the Nashville number for a note in the given key.
+// It goes by the note's letter, so an F# in D is a 3, unless that would
+// need a double sharp or flat, when it goes by pitch alone.
+func nashvilleDegree(note Chord, key Chord) string {
+	difference := (note.Root() - key.Root() + 12) % 12
+	degree := (strings.Index(noteLetters, note.Note) - strings.Index(noteLetters, key.Note) + 7) % 7
+	alter := (difference-majorScale[degree]+18)%12 - 6
+	switch alter {
+	case 0:
+		return strconv.Itoa(degree + 1)
+	case 1:
+		return "#" + strconv.Itoa(degree+1)
+	case -1:
+		return "b" + strconv.Itoa(degree+1)
+	}
+
+	return chromaticNumbers[difference]
+}
+
+// NashvilleNumber returns the chord as a Nashville number in the given key.
+// Minor chords are written with a dash, as 6-, if asked, or otherwise with
+// the chord's own suffix, as 6m.
+func (c Chord) NashvilleNumber(key Chord, minorDash bool) string {
+	if c.Root() < 0 || key.Root() < 0 {
+		return c.String()
+	}
+
+	flavor := c.Flavor
+	if minorDash && strings.HasPrefix(flavor, "m") && !strings.HasPrefix(flavor, "maj") {
+		flavor = "-" + flavor[1:]
+	}
+
+	res := nashvilleDegree(c, key) + flavor
+	if c.BassNote != nil && c.BassNote.Root() >= 0 {
+		res += "/" + nashvilleDegree(*c.BassNote, key)
+	}
+
+	return res
+}
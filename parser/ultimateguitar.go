@@ -0,0 +1,192 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+var chordTag = regexp.MustCompile(`\[ch\](.*?)\[/ch\]`)
+var tabTag = regexp.MustCompile(`\[/?tab\]`)
+var tabPair = regexp.MustCompile(`(?s)\[tab\].*?\[/tab\]`)
+var tablatureLine = regexp.MustCompile(`^\s*[A-Ga-g]?[#b]?\s*\|.*-.*|^[\s\-0-9|hpbrx/\\~()]*--[\s\-0-9|hpbrx/\\~()]*$`)
+
+const tabOpen = "[tab]"
+const tabClose = "[/tab]"
+
+// IsTablature reports whether the line is tablature rather than a note
+func IsTablature(line Line) bool {
+	return line.Type == LineTypes.TEXT && (strings.HasPrefix(line.Markup, tabOpen) || tablatureLine.MatchString(line.Text))
+}
+
+// hasUltimateGuitarMarkup reports whether the content has a tagged chord or
+// a [tab] which is closed, so a section called [Tab] isn't taken for one
+func hasUltimateGuitarMarkup(content string) bool {
+	return chordTag.MatchString(content) || tabPair.MatchString(content)
+}
+
+// tabbedLines reports which of the content's lines are in a [tab] which is
+// closed
+func tabbedLines(content string) []bool {
+	res := make([]bool, strings.Count(content, "\n")+1)
+	for _, span := range tabPair.FindAllStringIndex(content, -1) {
+		first := strings.Count(content[:span[0]], "\n")
+		last := first + strings.Count(content[span[0]:span[1]], "\n")
+		for index := first; index <= last; index++ {
+			res[index] = true
+		}
+	}
+
+	return res
+}
+
+type chordSpan struct {
+	column int
+	text   string
+}
+
+// stripChordTags removes the chord tags from a line, expanding its tabs, and
+// returns the chords which were tagged along with the columns they start at
+// once the tags are gone
+func stripChordTags(s string, tabWidth int) (string, []chordSpan) {
+	spans := make([]chordSpan, 0)
+	res := ""
+	last := 0
+	for _, match := range chordTag.FindAllStringSubmatchIndex(s, -1) {
+		res += expandTabs(s[last:match[0]], DisplayWidth(res), tabWidth)
+		chord := strings.TrimSpace(s[match[2]:match[3]])
+		if chord != "" {
+			spans = append(spans, chordSpan{column: DisplayWidth(res), text: chord})
+		}
+		res += chord
+		last = match[1]
+	}
+	res += expandTabs(s[last:], DisplayWidth(res), tabWidth)
+
+	return res, spans
+}
+
+// makeTaggedChord builds a chord which was tagged as one, so is a chord even
+// if it has a suffix MakeChord doesn't know. If it doesn't even start with a
+// note, it is left empty and won't be transposed.
+func makeTaggedChord(text string) Chord {
+	res := MakeChord(text)
+	if res.Note != "" || len(text) == 0 {
+		return res
+	}
+
+	if strings.ContainsAny(text, "♯♭") {
+		res = makeTaggedChord(accidentalSymbols.Replace(text))
+		res.useSymbols()
+		return res
+	}
+
+	lower := strings.ToLower(text)
+	if lower[0] < 'a' || lower[0] > 'g' {
+		return res
+	}
+
+	chord, bass, hasBass := strings.Cut(text, "/")
+	res.Note = strings.ToUpper(chord[:1])
+	rest := chord[1:]
+	if strings.HasPrefix(rest, "#") {
+		res.Accidental = AccidentalTypes.SHARP
+		rest = rest[1:]
+	} else if strings.HasPrefix(rest, "b") {
+		res.Accidental = AccidentalTypes.FLAT
+		rest = rest[1:]
+	}
+	res.Flavor = rest
+
+	if hasBass {
+		bassChord := makeTaggedChord(bass)
+		if bassChord.Note != "" {
+			res.BassNote = &bassChord
+		} else {
+			res.Flavor += "/" + bass
+		}
+	}
+
+	res.OriginalString = res.String()
+	res.OriginalAccidental = res.Accidental
+
+	return res
+}
+
+// makeTaggedLetterRuns splits a line into runs where the tagged chords are
+// chords, and nothing else is
+func makeTaggedLetterRuns(s string, spans []chordSpan) []LetterRun {
+	res := make([]LetterRun, 0)
+	width := DisplayWidth(s)
+	addUntagged := func(segment string) {
+		for _, run := range makeLetterRuns(segment) {
+			if run.Type == LetterRunTypes.CHORDRUN {
+				run = LetterRun{Letters: run.Letters, Type: LetterRunTypes.WORDRUN}
+			}
+			res = append(res, run)
+		}
+	}
+
+	start := 0
+	for _, span := range spans {
+		if span.column > start {
+			addUntagged(SliceColumns(s, start, span.column))
+		}
+
+		res = append(res, LetterRun{Letters: span.text, Type: LetterRunTypes.CHORDRUN, Chord: makeTaggedChord(span.text)})
+		start = span.column + DisplayWidth(span.text)
+	}
+
+	if start < width {
+		addUntagged(SliceColumns(s, start, width))
+	}
+
+	return res
+}
+
+// UltimateGuitarMarkup writes the runs of a chord line back out with its
+// chords tagged
+func UltimateGuitarMarkup(parts []LetterRun) string {
+	res := ""
+	for _, part := range parts {
+		if part.Type == LetterRunTypes.CHORDRUN {
+			res += "[ch]" + part.Letters + "[/ch]"
+		} else {
+			res += part.Letters
+		}
+	}
+
+	return strings.TrimRight(res, " \t\r\n")
+}
+
+// importUltimateGuitar reads text copied from Ultimate Guitar, with its
+// [ch] and [tab] tags stripped. A line which needs them to be read the same
+// way on its own keeps them in its Markup.
+func (p *ParsedContent) importUltimateGuitar(content string) error {
+	p.Lines = make([]Line, 0)
+	tabbed := tabbedLines(content)
+	for index, s := range strings.Split(content, "\n") {
+		s = strings.TrimRight(s, " \t\r\n")
+		lineInTab := tabbed[index]
+		if lineInTab {
+			s = tabTag.ReplaceAllString(s, "")
+		}
+
+		text, spans := stripChordTags(s, p.tabWidth())
+		text = strings.TrimRight(text, " \t\r\n")
+		line := Line{Text: text, Type: LineTypes.TEXT}
+
+		switch {
+		case len(spans) > 0:
+			line.Type = LineTypes.CHORDS
+			line.Parts = markAnnotations(markRhythm(makeTaggedLetterRuns(text, spans)))
+			line.Markup = UltimateGuitarMarkup(line.Parts)
+		case lineInTab && tablatureLine.MatchString(text):
+			line.Parts = makeLetterRuns("")
+			line.Markup = tabOpen + text + tabClose
+		}
+
+		p.Lines = append(p.Lines, line)
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const ultimateGuitarContent = `[Verse 1]
+[tab][ch]Am[/ch]       [ch]Cadd11[/ch]     [ch]G[/ch]
+Walking down the road[/tab]
+
+[Solo]
+[tab]e|-----0-----|
+B|---1---1---|[/tab]
+`
+
+func TestHasUltimateGuitarMarkup(t *testing.T) {
+	if !hasUltimateGuitarMarkup(ultimateGuitarContent) {
+		t.Errorf("Expected Ultimate Guitar markup to be found")
+	}
+
+	if hasUltimateGuitarMarkup(content) {
+		t.Errorf("Expected plain content to have no Ultimate Guitar markup")
+	}
+}
+
+func TestStripChordTags(t *testing.T) {
+	text, spans := stripChordTags("[ch]Am[/ch]   [ch] C [/ch] x2", DefaultTabWidth)
+	if text != "Am   C x2" {
+		t.Errorf("Expected stripped text %#v, got %#v", "Am   C x2", text)
+	}
+
+	expected := []chordSpan{{column: 0, text: "Am"}, {column: 5, text: "C"}}
+	if !reflect.DeepEqual(spans, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, spans)
+	}
+}
+
+func TestMakeTaggedChord(t *testing.T) {
+	for _, check := range []struct {
+		source   string
+		expected string
+	}{
+		{"Am", "Am"},
+		{"Cadd11", "Cadd11"},
+		{"F#m(maj7)", "F#m(maj7)"},
+		{"Bbadd11/D", "Bbadd11/D"},
+		{"N.C.", ""},
+		{"x", ""},
+	} {
+		c := makeTaggedChord(check.source)
+		if c.String() != check.expected {
+			t.Errorf("Expected %#v for %#v, got %#v", check.expected, check.source, c.String())
+		}
+	}
+
+	c := makeTaggedChord("Cadd11")
+	c.StepUp()
+	if c.String() != "C#add11" {
+		t.Errorf("Expected a tagged chord to transpose, got %#v", c.String())
+	}
+}
+
+func TestParseUltimateGuitar(t *testing.T) {
+	parser := ParsedContent{}
+	err := parser.ParseContent(ultimateGuitarContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	types := make([]LineType, len(parser.Lines))
+	texts := make([]string, len(parser.Lines))
+	for index, line := range parser.Lines {
+		types[index] = line.Type
+		texts[index] = line.Text
+	}
+
+	expectedTypes := []LineType{LineTypes.SECTION, LineTypes.CHORDS, LineTypes.LYRICS, LineTypes.EMPTY, LineTypes.SECTION, LineTypes.TEXT, LineTypes.TEXT}
+	expectedTexts := []string{"[Verse 1]", "Am       Cadd11     G", "Walking down the road", "", "[Solo]", "e|-----0-----|", "B|---1---1---|"}
+	if !reflect.DeepEqual(types, expectedTypes) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expectedTypes, types)
+	}
+
+	if !reflect.DeepEqual(texts, expectedTexts) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expectedTexts, texts)
+	}
+
+	if parser.Lines[1].Markup != "[ch]Am[/ch]       [ch]Cadd11[/ch]     [ch]G[/ch]" {
+		t.Errorf("Unexpected chord line markup %#v", parser.Lines[1].Markup)
+	}
+
+	if parser.Lines[5].Markup != "[tab]e|-----0-----|[/tab]" {
+		t.Errorf("Unexpected tablature markup %#v", parser.Lines[5].Markup)
+	}
+}
+
+func TestUltimateGuitarMarkupReparses(t *testing.T) {
+	parser := ParsedContent{}
+	err := parser.ParseContent(ultimateGuitarContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range parser.Lines {
+		if line.Markup == "" {
+			continue
+		}
+
+		again := ParsedContent{}
+		err = again.ParseContent(line.Markup)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(again.Lines[0].Parts, line.Parts) || again.Lines[0].Type != line.Type {
+			t.Errorf("Expected %#v to parse the same on its own", line.Markup)
+		}
+	}
+}
+
+func TestTransposeUltimateGuitar(t *testing.T) {
+	parser := ParsedContent{}
+	err := parser.ParseContent(ultimateGuitarContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser.TransposeUpOneStep()
+	expected := []string{"[Verse 1]", "A#m      C#add11    G#", "Walking down the road", "", "[Solo]", "e|-----0-----|", "B|---1---1---|"}
+	asString := make([]string, len(parser.Lines))
+	for index, line := range parser.Lines {
+		asString[index] = line.String()
+	}
+
+	if !reflect.DeepEqual(asString, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, asString)
+	}
+}
+
+func TestTransposeSkipsNoChord(t *testing.T) {
+	parser := ParsedContent{}
+	err := parser.ParseContent("N.C.   C\nwords here\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parser.TransposeUpOneStep()
+	if parser.Lines[0].String() != "N.C.   C#" {
+		t.Errorf("Expected N.C. to be left alone, got %#v", parser.Lines[0].String())
+	}
+}
+
+func TestSectionsNamedLikeTagsAreNotMarkup(t *testing.T) {
+	for _, song := range []string{"[Tab]\nC  G\nhello\n", "[CH]\nC  G\nhello\n", "[tab]\nC  G\nhello\n"} {
+		if hasUltimateGuitarMarkup(song) {
+			t.Errorf("Expected %#v to have no Ultimate Guitar markup", song)
+		}
+
+		parser := ParsedContent{}
+		err := parser.ParseContent(song)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		header := song[:strings.Index(song, "\n")]
+		if len(parser.Lines) == 0 || parser.Lines[0].Text != header || parser.Lines[0].Type != LineTypes.SECTION {
+			t.Errorf("Expected %#v to be kept as a section header, got:\n'%#v'", header, parser.Lines)
+		}
+	}
+}
+
+func TestUnclosedTabIsKept(t *testing.T) {
+	parser := ParsedContent{}
+	err := parser.ParseContent("[tab]\n[ch]C[/ch]  [ch]G[/ch]\nhello\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := []string{parser.Lines[0].Text, parser.Lines[1].String()}
+	expected := []string{"[tab]", "C  G"}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, res)
+	}
+}
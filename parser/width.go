@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// DefaultTabWidth is how far apart tab stops are when a song doesn't say
+const DefaultTabWidth = 8
+
+// graphemes calls the function with each grapheme cluster in the text, and
+// the columns it takes up
+func graphemes(s string, each func(cluster string, width int)) {
+	state := -1
+	for len(s) > 0 {
+		cluster, rest, width, newState := uniseg.FirstGraphemeClusterInString(s, state)
+		if width == 0 && strings.ContainsAny(cluster, "\t\r\n") {
+			width = 1
+		}
+
+		each(cluster, width)
+		s, state = rest, newState
+	}
+}
+
+// DisplayWidth returns the number of columns the text takes up in a monospace font
+func DisplayWidth(s string) int {
+	res := 0
+	graphemes(s, func(_ string, width int) {
+		res += width
+	})
+
+	return res
+}
+
+// SliceColumns returns the part of the text which starts at or after the
+// column from, and before the column to
+func SliceColumns(s string, from int, to int) string {
+	var res strings.Builder
+	column := 0
+	graphemes(s, func(cluster string, width int) {
+		if column >= from && column < to {
+			res.WriteString(cluster)
+		}
+		column += width
+	})
+
+	return res.String()
+}
+
+// PadToWidth pads the text with spaces until it takes up the given width
+func PadToWidth(s string, width int) string {
+	return s + strings.Repeat(" ", max(0, width-DisplayWidth(s)))
+}
+
+// expandTabs replaces the tabs in the text with spaces up to the next tab
+// stop, for text which starts at the given column
+func expandTabs(s string, column int, tabWidth int) string {
+	if tabWidth < 1 || !strings.Contains(s, "\t") {
+		return s
+	}
+
+	var res strings.Builder
+	graphemes(s, func(cluster string, width int) {
+		switch cluster {
+		case "\t":
+			spaces := tabWidth - column%tabWidth
+			res.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+		case "\n", "\r\n":
+			res.WriteString(cluster)
+			column = 0
+		default:
+			res.WriteString(cluster)
+			column += width
+		}
+	})
+
+	return res.String()
+}
+
+// ExpandTabs replaces the tabs in the text with spaces up to the next tab
+// stop, with stops every tabWidth columns
+func ExpandTabs(s string, tabWidth int) string {
+	return expandTabs(s, 0, tabWidth)
+}
+
+// tabWidth returns how far apart tab stops are in the content's text
+func (p ParsedContent) tabWidth() int {
+	if p.TabWidth < 1 {
+		return DefaultTabWidth
+	}
+
+	return p.TabWidth
+}
@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	cases := map[string]int{
+		"Cafe":           4,
+		"Café":           4,
+		"Café":          4,
+		"“quoted”":       8,
+		"日本語":            6,
+		"C♯m":            3,
+		"a\tb":           3,
+		"":               0,
+		"👍🏽 thumbs":      9,
+		"Ünïcödé lyrics": 14,
+	}
+
+	for text, expected := range cases {
+		if DisplayWidth(text) != expected {
+			t.Errorf("Expected %q to be %d wide, got %d", text, expected, DisplayWidth(text))
+		}
+	}
+}
+
+func TestSliceColumns(t *testing.T) {
+	text := "Été 日本語 song"
+	got := []string{SliceColumns(text, 0, 4), SliceColumns(text, 4, 8), SliceColumns(text, 8, 20)}
+	expected := []string{"Été ", "日本", "語 song"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, got)
+	}
+}
+
+func TestMakeLetterRunsKeepsUnicode(t *testing.T) {
+	text := "Café “naïve” 日本語の歌"
+	runs := makeLetterRuns(text)
+	joined := strings.Join(lo.Map(runs, func(run LetterRun, _ int) string {
+		return run.Letters
+	}), "")
+
+	if joined != text {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", text, joined)
+	}
+
+	if readAsChords(text) {
+		t.Errorf("Expected %q not to be read as chords", text)
+	}
+}
+
+func TestChordSymbols(t *testing.T) {
+	for _, chord := range []string{"C♯", "B♭7", "CΔ", "CΔ7", "E♭Δ9", "Bø", "Bø7", "C°", "F♯°7", "Am/G♯"} {
+		if !isChord(chord) {
+			t.Errorf("Expected %q to be a chord", chord)
+		}
+	}
+
+	cases := map[string]string{
+		"C♯m": "minor",
+		"CΔ7": "major-seventh",
+		"CΔ":  "major-seventh",
+		"Bø7": "half-diminished",
+		"C°":  "diminished",
+		"C°7": "diminished-seventh",
+	}
+	for chord, kind := range cases {
+		if MakeChord(chord).Quality().Kind != kind {
+			t.Errorf("Expected %q to be %s, got %s", chord, kind, MakeChord(chord).Quality().Kind)
+		}
+	}
+
+	chord := MakeChord("B♭Δ7/D")
+	if chord.Root() != 10 || chord.Bass() != 2 || chord.String() != "B♭Δ7/D" {
+		t.Errorf("Expected B♭Δ7/D, got %q", chord.String())
+	}
+}
+
+func TestTransposeChordSymbols(t *testing.T) {
+	content := ParsedContent{}
+	err := content.ParseContent("CΔ7  B♭   F♯ø7\nLyrics go here\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content.Transpose(2)
+	if content.Lines[0].String() != "DΔ7  C    G♯ø7" {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", "DΔ7  C    G♯ø7", content.Lines[0].String())
+	}
+}
+
+func TestChordColumnsOverNonASCIILyrics(t *testing.T) {
+	content := ParsedContent{}
+	err := content.ParseContent("C   G     Am\n日本語の歌です\nC   G\nÉté “là” bas\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leading, lyrics := LyricsUnder(content.Lines[0].PlacedChords(), content.Lines[1].Text)
+	expected := []string{"日本", "語の歌", "です"}
+	if leading != "" || !reflect.DeepEqual(lyrics, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, lyrics)
+	}
+
+	_, lyrics = LyricsUnder(content.Lines[2].PlacedChords(), content.Lines[3].Text)
+	expected = []string{"Été ", "“là” bas"}
+	if !reflect.DeepEqual(lyrics, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, lyrics)
+	}
+}
+
+func TestExpandTabs(t *testing.T) {
+	cases := map[string]string{
+		"G\tC\tD":        "G   C   D",
+		"\tSing":         "    Sing",
+		"日本\t語":          "日本    語",
+		"Am7\t\tC\nG\tD": "Am7     C\nG   D",
+		"No tabs":        "No tabs",
+	}
+
+	for text, expected := range cases {
+		if ExpandTabs(text, 4) != expected {
+			t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, ExpandTabs(text, 4))
+		}
+	}
+}
+
+func TestTabbedChordsLineUpWithLyrics(t *testing.T) {
+	for _, text := range []string{
+		"G\tC\tD7\nI sing   to you\n",
+		"[ch]G[/ch]\t[ch]C[/ch]\t[ch]D7[/ch]\nI sing   to you\n",
+	} {
+		content := ParsedContent{TabWidth: 4}
+		err := content.ParseContent(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		chords := content.Lines[0].PlacedChords()
+		_, lyrics := LyricsUnder(chords, content.Lines[1].Text)
+		expected := []string{"I si", "ng  ", " to you"}
+		if !reflect.DeepEqual(lyrics, expected) {
+			t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, lyrics)
+		}
+
+		content.Transpose(1)
+		if content.Lines[0].String() != "G#  C#  D#7" {
+			t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", "G#  C#  D#7", content.Lines[0].String())
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"wails-lead-sheet/parser"
+)
+
+// renumber drops empty lines which would be doubled up, or at the start or
+// end, once other lines are gone, and numbers the lines again
+func renumber(lines []parser.Line) []parser.Line {
+	res := make([]parser.Line, 0, len(lines))
+	for _, line := range lines {
+		if line.Type == parser.LineTypes.EMPTY && (len(res) == 0 || res[len(res)-1].Type == parser.LineTypes.EMPTY) {
+			continue
+		}
+
+		res = append(res, line)
+	}
+
+	if len(res) > 0 && res[len(res)-1].Type == parser.LineTypes.EMPTY {
+		res = res[:len(res)-1]
+	}
+
+	for index := range res {
+		res[index].LineNumber = index
+	}
+
+	return res
+}
+
+// LyricsOnly returns the content without its chords, for a lyric sheet.
+// Chord lines and tablature go, and so do sections left with no lyrics or
+// notes in them, and the stanzas are spaced as they were.
+func LyricsOnly(content parser.ParsedContent) parser.ParsedContent {
+	kept := make([]parser.Line, 0, len(content.Lines))
+	for _, line := range content.Lines {
+		if line.Type == parser.LineTypes.CHORDS || parser.IsTablature(line) {
+			continue
+		}
+
+		kept = append(kept, line)
+	}
+
+	lines := make([]parser.Line, 0, len(kept))
+	for index, line := range kept {
+		if line.Type == parser.LineTypes.SECTION {
+			hasLyrics := false
+			for _, next := range kept[index+1:] {
+				if next.Type == parser.LineTypes.SECTION {
+					break
+				}
+				hasLyrics = hasLyrics || next.Type == parser.LineTypes.LYRICS || next.Type == parser.LineTypes.TEXT
+			}
+
+			if !hasLyrics {
+				continue
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	content.Lines = renumber(lines)
+
+	return content
+}
+
+// ChordsOnly returns the content without its lyrics, for the rhythm
+// section. Sections are kept, and a chord line repeated straight after
+// itself is written once, marked as (x3).
+func ChordsOnly(content parser.ParsedContent) parser.ParsedContent {
+	lines := make([]parser.Line, 0, len(content.Lines))
+	counts := make([]int, 0, len(content.Lines))
+	for _, line := range content.Lines {
+		if line.Type == parser.LineTypes.LYRICS {
+			continue
+		}
+
+		last := len(lines) - 1
+		if line.Type == parser.LineTypes.CHORDS && last >= 0 && lines[last].Type == parser.LineTypes.CHORDS &&
+			lines[last].String() == line.String() {
+			counts[last] += 1
+			continue
+		}
+
+		lines = append(lines, line)
+		counts = append(counts, 1)
+	}
+
+	for index, count := range counts {
+		if count > 1 {
+			line := lines[index]
+			mark := fmt.Sprintf("(x%d)", count*max(1, line.Repeat))
+			line.Parts = append(append([]parser.LetterRun{}, line.Parts...),
+				parser.LetterRun{Type: parser.LetterRunTypes.SEPARATORRUN, Letters: "  ", OriginalLetters: "  "},
+				parser.LetterRun{Type: parser.LetterRunTypes.ANNOTATIONRUN, Letters: mark, OriginalLetters: mark})
+			line.Text += "  " + mark
+			line.Repeat = count * max(1, line.Repeat)
+			lines[index] = line
+		}
+	}
+
+	content.Lines = renumber(lines)
+
+	return content
+}
+
+// Text renders the content as plain text, as it's shown
+func Text(content parser.ParsedContent) string {
+	var out strings.Builder
+	for _, line := range content.Lines {
+		out.WriteString(line.String() + "\n")
+	}
+
+	return out.String()
+}
+
+// WriteText renders the content as plain text to the writer
+func WriteText(out io.Writer, content parser.ParsedContent) error {
+	_, err := io.WriteString(out, Text(content))
+	return err
+}
@@ -0,0 +1,116 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+
+	"wails-lead-sheet/parser"
+)
+
+const filterSong = `[Intro]
+C   G
+
+[Verse]
+C       G
+First line here
+Am      F
+Second line here
+
+C       G
+Third line here
+
+[Chorus]
+F   G
+Sing it
+F   G
+Sing it
+F   G
+Sing it
+`
+
+func lineTexts(content parser.ParsedContent) []string {
+	res := make([]string, len(content.Lines))
+	for index, line := range content.Lines {
+		res[index] = line.String()
+	}
+
+	return res
+}
+
+func TestLyricsOnly(t *testing.T) {
+	content := LyricsOnly(parse(t, filterSong))
+
+	expected := []string{
+		"[Verse]",
+		"First line here",
+		"Second line here",
+		"",
+		"Third line here",
+		"",
+		"[Chorus]",
+		"Sing it",
+		"Sing it",
+		"Sing it",
+	}
+	if !reflect.DeepEqual(lineTexts(content), expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, lineTexts(content))
+	}
+
+	for index, line := range content.Lines {
+		if line.LineNumber != index {
+			t.Errorf("Expected line %d to be numbered %d, got %d", index, index, line.LineNumber)
+		}
+	}
+}
+
+func TestChordsOnly(t *testing.T) {
+	content := ChordsOnly(parse(t, filterSong))
+
+	expected := []string{
+		"[Intro]",
+		"C   G",
+		"",
+		"[Verse]",
+		"C       G",
+		"Am      F",
+		"",
+		"C       G",
+		"",
+		"[Chorus]",
+		"F   G  (x3)",
+	}
+	if !reflect.DeepEqual(lineTexts(content), expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, lineTexts(content))
+	}
+
+	if content.Lines[10].Repeat != 3 || len(content.Progression()) != 14 {
+		t.Errorf("Expected the chorus to be played three times, got %d chords", len(content.Progression()))
+	}
+}
+
+func TestChordsOnlyTransposed(t *testing.T) {
+	content := parse(t, "C  G\nOne\nC  G\nTwo\n")
+	content.Transpose(1)
+	res := Text(ChordsOnly(content))
+
+	if res != "C# G#  (x2)\n" {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", "C# G#  (x2)\n", res)
+	}
+}
+
+func TestLyricsOnlyKeepsNotes(t *testing.T) {
+	content := parser.ParsedContent{Overrides: []parser.Override{
+		parser.MakeOverride("Repeat chorus softly", parser.LineTypes.TEXT),
+		parser.MakeOverride("e|---0---3---|", parser.LineTypes.TEXT),
+	}}
+	err := content.ParseContent("[Verse]\nC       G\nFirst line here\n\n[Solo]\ne|---0---3---|\n\n[Outro]\nRepeat chorus softly\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"[Verse]", "First line here", "", "[Outro]", "Repeat chorus softly"}
+	res := lineTexts(LyricsOnly(content))
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, res)
+	}
+}
@@ -0,0 +1,232 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strings"
+
+	"wails-lead-sheet/parser"
+)
+
+// gridHold marks a beat where the chord before it carries on
+const gridHold = "."
+
+// GridOptions control the chord grid output
+type GridOptions struct {
+	Title      string
+	Key        string
+	BarsPerRow int
+	Standalone bool
+}
+
+// DefaultGridOptions returns the options for a chord grid of four bars a row
+func DefaultGridOptions() GridOptions {
+	return GridOptions{BarsPerRow: 4}
+}
+
+// gridSection is a section of the song as bars, each with a slot per beat
+// holding the chord which starts on it, if one does
+type gridSection struct {
+	name string
+	bars [][]string
+}
+
+// gridSections lays out the song's chords in bars, a section at a time,
+// each written as the label gives. A chord lasts as long as the chart
+// says, or a bar if it doesn't, and each section starts on a new bar.
+func gridSections(content parser.ParsedContent, label func(parser.ProgressionChord) string) []gridSection {
+	beatsPerBar, _ := content.Metadata.Meter()
+	res := make([]gridSection, 0)
+	position := 0.0
+
+	finish := func() {
+		if len(res) == 0 {
+			return
+		}
+
+		section := &res[len(res)-1]
+		for _, bar := range section.bars {
+			for slot := range bar {
+				if bar[slot] == "" {
+					bar[slot] = gridHold
+				}
+			}
+		}
+	}
+
+	for index, chord := range content.Progression() {
+		if index == 0 || chord.StartsSection {
+			finish()
+			res = append(res, gridSection{name: chord.Section})
+			position = 0
+		}
+
+		section := &res[len(res)-1]
+		bar := int(math.Floor(position/float64(beatsPerBar) + 1e-9))
+		slot := int(math.Round(position - float64(bar*beatsPerBar)))
+		if slot >= beatsPerBar {
+			bar, slot = bar+1, 0
+		}
+
+		beats := chord.Beats
+		if beats <= 0 {
+			beats = float64(beatsPerBar)
+		}
+		position += beats
+
+		bars := int(math.Ceil(position/float64(beatsPerBar) - 1e-9))
+		for len(section.bars) < max(bars, bar+1) {
+			section.bars = append(section.bars, make([]string, beatsPerBar))
+		}
+
+		if section.bars[bar][slot] != "" {
+			section.bars[bar][slot] += " " + label(chord)
+		} else {
+			section.bars[bar][slot] = label(chord)
+		}
+	}
+	finish()
+
+	return res
+}
+
+func chordText(chord parser.ProgressionChord) string {
+	return chord.Text
+}
+
+func gridWidth(sections []gridSection) int {
+	width := 1
+	for _, section := range sections {
+		for _, bar := range section.bars {
+			for _, slot := range bar {
+				width = max(width, parser.DisplayWidth(slot))
+			}
+		}
+	}
+
+	return width
+}
+
+func gridRows(bars [][]string, barsPerRow int) [][][]string {
+	if barsPerRow < 1 {
+		barsPerRow = DefaultGridOptions().BarsPerRow
+	}
+
+	res := make([][][]string, 0)
+	for start := 0; start < len(bars); start += barsPerRow {
+		res = append(res, bars[start:min(start+barsPerRow, len(bars))])
+	}
+
+	return res
+}
+
+// gridHeading returns the key and time signature line shown above a grid
+func gridHeading(content parser.ParsedContent, key string) string {
+	parts := make([]string, 0)
+	if key != "" {
+		parts = append(parts, "Key: "+key)
+	}
+
+	if content.Metadata.TimeSignature != "" {
+		beats, beatType := content.Metadata.Meter()
+		parts = append(parts, fmt.Sprintf("Time: %d/%d", beats, beatType))
+	}
+
+	return strings.Join(parts, "  ")
+}
+
+// GridText renders the song's chords as a fixed width bar grid, like
+// | C . . . | G . . . |
+func GridText(content parser.ParsedContent, options GridOptions) string {
+	var out strings.Builder
+	if options.Title != "" {
+		out.WriteString(options.Title + "\n")
+	}
+
+	heading := gridHeading(content, options.Key)
+	if heading != "" {
+		out.WriteString(heading + "\n")
+	}
+
+	sections := gridSections(content, chordText)
+	width := gridWidth(sections)
+	for index, section := range sections {
+		if index > 0 || out.Len() > 0 {
+			out.WriteString("\n")
+		}
+
+		if section.name != "" {
+			out.WriteString("[" + section.name + "]\n")
+		}
+
+		for _, row := range gridRows(section.bars, options.BarsPerRow) {
+			for _, bar := range row {
+				out.WriteString("| ")
+				for _, slot := range bar {
+					out.WriteString(parser.PadToWidth(slot, width+1))
+				}
+			}
+			out.WriteString("|\n")
+		}
+	}
+
+	return out.String()
+}
+
+// GridHTML renders the song's chords as a bar grid in an HTML table
+func GridHTML(content parser.ParsedContent, options GridOptions) string {
+	var out strings.Builder
+	writeHTMLStart(&out, options.Title, options.Key, options.Standalone)
+
+	heading := gridHeading(content, "")
+	if heading != "" {
+		fmt.Fprintf(&out, "<p class=\"meter\">%s</p>\n", html.EscapeString(heading))
+	}
+
+	for _, section := range gridSections(content, chordText) {
+		fmt.Fprintf(&out, "<section class=\"%s\">\n", cssClass(parser.LineTypes.SECTION))
+		if section.name != "" {
+			fmt.Fprintf(&out, "<h2>%s</h2>\n", html.EscapeString(section.name))
+		}
+
+		out.WriteString("<table class=\"grid\">\n")
+		for _, row := range gridRows(section.bars, options.BarsPerRow) {
+			out.WriteString("<tr>")
+			for _, bar := range row {
+				out.WriteString("<td class=\"bar\">")
+				for index, slot := range bar {
+					if index > 0 {
+						out.WriteString(" ")
+					}
+
+					if slot == gridHold {
+						out.WriteString(`<span class="hold">.</span>`)
+					} else {
+						fmt.Fprintf(&out, `<span class="%s">%s</span>`, cssClass(parser.LetterRunTypes.CHORDRUN), html.EscapeString(slot))
+					}
+				}
+				out.WriteString("</td>")
+			}
+			out.WriteString("</tr>\n")
+		}
+		out.WriteString("</table>\n</section>\n")
+	}
+
+	writeHTMLEnd(&out, options.Standalone)
+
+	return out.String()
+}
+
+// WriteGridText renders the song as a text chord grid to the writer
+func WriteGridText(out io.Writer, content parser.ParsedContent, options GridOptions) error {
+	_, err := io.WriteString(out, GridText(content, options))
+	return err
+}
+
+// WriteGridHTML renders the song as an HTML chord grid to the writer
+func WriteGridHTML(out io.Writer, content parser.ParsedContent, options GridOptions) error {
+	_, err := io.WriteString(out, GridHTML(content, options))
+	return err
+}
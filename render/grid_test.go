@@ -0,0 +1,57 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGridTextOneBarPerChord(t *testing.T) {
+	content := parse(t, "[Verse]\nC   G   Am   F\nWords go here for all\nC\n[Chorus]\nF   G\n")
+	res := GridText(content, GridOptions{BarsPerRow: 4, Key: "C"})
+
+	expected := `Key: C
+
+[Verse]
+| C  .  .  .  | G  .  .  .  | Am .  .  .  | F  .  .  .  |
+| C  .  .  .  |
+
+[Chorus]
+| F  .  .  .  | G  .  .  .  |
+`
+	if res != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, res)
+	}
+}
+
+func TestGridTextFollowsBeats(t *testing.T) {
+	content := parse(t, "| C / G / | Am | | F . . (x2)\n")
+	content.Metadata.TimeSignature = "3/4"
+	res := GridText(content, GridOptions{BarsPerRow: 3})
+
+	expected := `Time: 3/4
+
+| C  .  G  | .  Am .  | .  .  .  |
+| .  F  .  | .  C  .  | G  .  Am |
+| .  .  .  | .  .  F  | .  .  .  |
+`
+	if res != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, res)
+	}
+}
+
+func TestGridHTML(t *testing.T) {
+	content := parse(t, "[Intro]\nC / G /\n")
+	res := GridHTML(content, GridOptions{Title: "Song", BarsPerRow: 4, Standalone: true})
+
+	expected := []string{
+		"<title>Song</title>",
+		"<h2>Intro</h2>",
+		`<td class="bar"><span class="chordrun">C</span> <span class="hold">.</span> <span class="chordrun">G</span> <span class="hold">.</span></td>`,
+		"</html>",
+	}
+	for _, text := range expected {
+		if !strings.Contains(res, text) {
+			t.Errorf("Expected:\n%s\nin:\n%s", text, res)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"wails-lead-sheet/parser"
+)
+
+// HTMLStyle is the style sheet used for standalone HTML output. Chords sit
+// above the syllable they belong to, and a line reflows onto more rows on
+// narrow screens without losing that.
+const HTMLStyle = `.song { font-family: Georgia, serif; line-height: 1.3; max-width: 50em; margin: 0 auto; padding: 1em; }
+.song header { display: flex; justify-content: space-between; align-items: baseline; border-bottom: 1px solid #999; }
+.song h1 { font-size: 1.5em; margin: 0; }
+.song .key { margin: 0; }
+.song .section { margin: 1em 0; }
+.song .section h2 { font-size: 1.1em; margin: 0 0 0.3em 0; }
+.song .stanza { margin-bottom: 0.8em; }
+.song .line { display: flex; flex-wrap: wrap; align-items: flex-end; margin: 0; }
+.song .pair { display: inline-flex; flex-direction: column; }
+.song .chordrun { font-weight: bold; color: #1a4099; white-space: pre; }
+.song .pair .chordrun { min-height: 1.3em; padding-right: 0.4em; }
+.song .annotationrun { font-weight: normal; font-style: italic; color: #666; white-space: pre; }
+.song .lyric, .song .lyrics, .song .chords { white-space: pre; }
+.song .text { white-space: pre; font-family: monospace; }
+.song .grid { border-collapse: collapse; margin-bottom: 0.8em; }
+.song .grid .bar { border-left: 1px solid #333; border-right: 1px solid #333; padding: 0.2em 0.6em; min-width: 6em; font-family: monospace; white-space: pre; }
+.song .grid .hold { color: #999; }
+@media (max-width: 30em) {
+  .song { font-size: 0.9em; padding: 0.5em; }
+  .song .lyric { white-space: pre-wrap; }
+}
+`
+
+// HTMLOptions control the HTML output. A standalone page includes its own
+// style sheet; otherwise just the song's markup is written, to go inside
+// another page.
+type HTMLOptions struct {
+	Title      string
+	Key        string
+	Standalone bool
+}
+
+func cssClass(typ fmt.Stringer) string {
+	return strings.ToLower(typ.String())
+}
+
+func writeAnnotation(out *strings.Builder, text string) {
+	fmt.Fprintf(out, `<span class="%s">%s</span>`, cssClass(parser.LetterRunTypes.ANNOTATIONRUN), html.EscapeString(text))
+}
+
+// notesAfter returns the annotations on a chord line after the given part,
+// up to the next chord
+func notesAfter(line parser.Line, part int) []string {
+	res := make([]string, 0)
+	for index := part + 1; index < len(line.Parts) && line.Parts[index].Type != parser.LetterRunTypes.CHORDRUN; index++ {
+		if line.Parts[index].Type == parser.LetterRunTypes.ANNOTATIONRUN {
+			res = append(res, line.Parts[index].Letters)
+		}
+	}
+
+	return res
+}
+
+func writePair(out *strings.Builder, chord string, notes []string, lyric string) {
+	out.WriteString(`<span class="pair">`)
+	fmt.Fprintf(out, `<span class="%s">%s`, cssClass(parser.LetterRunTypes.CHORDRUN), html.EscapeString(chord))
+	for _, note := range notes {
+		out.WriteString(" ")
+		writeAnnotation(out, note)
+	}
+	out.WriteString(`</span>`)
+	if lyric == "" {
+		lyric = " "
+	}
+	fmt.Fprintf(out, `<span class="lyric">%s</span>`, html.EscapeString(lyric))
+	out.WriteString(`</span>`)
+}
+
+// writeChordLyricLine writes a chord line and the lyric line under it as
+// pairs, each chord anchored to the lyrics which start at its column
+func writeChordLyricLine(out *strings.Builder, chords parser.Line, lyrics parser.Line) {
+	anchored := chords.PlacedChords()
+	leading, under := parser.LyricsUnder(anchored, lyrics.Text)
+	fmt.Fprintf(out, `<div class="line %s">`, cssClass(parser.LineTypes.LYRICS))
+
+	leadingNotes := notesAfter(chords, -1)
+	if leading != "" || len(leadingNotes) > 0 {
+		writePair(out, "", leadingNotes, leading)
+	}
+
+	for index, chord := range anchored {
+		writePair(out, chord.Chord, notesAfter(chords, chord.Part), under[index])
+	}
+
+	out.WriteString("</div>\n")
+}
+
+func writeChordLine(out *strings.Builder, line parser.Line) {
+	fmt.Fprintf(out, `<div class="line %s">`, cssClass(parser.LineTypes.CHORDS))
+	for _, part := range line.Parts {
+		if part.Type == parser.LetterRunTypes.CHORDRUN {
+			text := part.ShownLetters()
+			fmt.Fprintf(out, `<span class="%s">%s</span>`, cssClass(part.Type), html.EscapeString(text))
+			if part.TransposedLetters != "" {
+				out.WriteString(strings.Repeat(" ", parser.DisplayWidth(part.TransposedLetters)-parser.DisplayWidth(text)))
+			}
+			continue
+		}
+
+		if part.Type == parser.LetterRunTypes.ANNOTATIONRUN {
+			writeAnnotation(out, part.Letters)
+			continue
+		}
+
+		out.WriteString(html.EscapeString(part.Letters))
+	}
+	out.WriteString("</div>\n")
+}
+
+// writeHTMLStart writes the start of the song's article, with its title
+// and key, and for a standalone page everything which goes before it
+func writeHTMLStart(out *strings.Builder, title string, key string, standalone bool) {
+	if standalone {
+		out.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+		out.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+		fmt.Fprintf(out, "<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n", html.EscapeString(title), HTMLStyle)
+	}
+
+	out.WriteString("<article class=\"song\">\n")
+	if title != "" || key != "" {
+		out.WriteString("<header>")
+		fmt.Fprintf(out, "<h1>%s</h1>", html.EscapeString(title))
+		if key != "" {
+			fmt.Fprintf(out, "<p class=\"key\">Key: %s</p>", html.EscapeString(key))
+		}
+		out.WriteString("</header>\n")
+	}
+}
+
+func writeHTMLEnd(out *strings.Builder, standalone bool) {
+	out.WriteString("</article>\n")
+
+	if standalone {
+		out.WriteString("</body>\n</html>\n")
+	}
+}
+
+// HTML renders the content as HTML
+func HTML(content parser.ParsedContent, options HTMLOptions) string {
+	var out strings.Builder
+	writeHTMLStart(&out, options.Title, options.Key, options.Standalone)
+
+	inSection := false
+	inStanza := false
+	closeStanza := func() {
+		if inStanza {
+			out.WriteString("</div>\n")
+			inStanza = false
+		}
+	}
+	openStanza := func() {
+		if !inSection {
+			fmt.Fprintf(&out, "<section class=\"%s\">\n", cssClass(parser.LineTypes.SECTION))
+			inSection = true
+		}
+
+		if !inStanza {
+			out.WriteString("<div class=\"stanza\">\n")
+			inStanza = true
+		}
+	}
+
+	for index := 0; index < len(content.Lines); index++ {
+		line := content.Lines[index]
+		switch line.Type {
+		case parser.LineTypes.EMPTY:
+			closeStanza()
+		case parser.LineTypes.SECTION:
+			closeStanza()
+			if inSection {
+				out.WriteString("</section>\n")
+			}
+
+			name := strings.Trim(strings.TrimSpace(line.Text), "[]")
+			fmt.Fprintf(&out, "<section class=\"%s\">\n<h2>%s</h2>\n", cssClass(line.Type), html.EscapeString(name))
+			inSection = true
+		case parser.LineTypes.CHORDS:
+			openStanza()
+			if index+1 < len(content.Lines) && content.Lines[index+1].Type == parser.LineTypes.LYRICS {
+				writeChordLyricLine(&out, line, content.Lines[index+1])
+				index += 1
+			} else {
+				writeChordLine(&out, line)
+			}
+		default:
+			openStanza()
+			fmt.Fprintf(&out, "<p class=\"line %s\">%s</p>\n", cssClass(line.Type), html.EscapeString(line.String()))
+		}
+	}
+
+	closeStanza()
+	if inSection {
+		out.WriteString("</section>\n")
+	}
+	writeHTMLEnd(&out, options.Standalone)
+
+	return out.String()
+}
+
+// WriteHTML renders the content as HTML to the writer
+func WriteHTML(out io.Writer, content parser.ParsedContent, options HTMLOptions) error {
+	_, err := io.WriteString(out, HTML(content, options))
+	return err
+}
@@ -0,0 +1,99 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLAnchorsChordsToSyllables(t *testing.T) {
+	content := parse(t, "[Verse 1]\n  G     D\nI walked along\n")
+	res := HTML(content, HTMLOptions{})
+
+	expected := `<div class="line lyrics">` +
+		`<span class="pair"><span class="chordrun"></span><span class="lyric">I </span></span>` +
+		`<span class="pair"><span class="chordrun">G</span><span class="lyric">walked</span></span>` +
+		`<span class="pair"><span class="chordrun">D</span><span class="lyric"> along</span></span>` +
+		"</div>\n"
+	if !strings.Contains(res, expected) {
+		t.Errorf("Expected:\n%s\nin:\n%s", expected, res)
+	}
+
+	if !strings.Contains(res, "<section class=\"section\">\n<h2>Verse 1</h2>\n") {
+		t.Errorf("Expected a section block, got:\n%s", res)
+	}
+}
+
+func TestHTMLChordsPastTheLyrics(t *testing.T) {
+	content := parse(t, "C      G      Am\nShort\n")
+	res := HTML(content, HTMLOptions{})
+
+	expected := `<span class="pair"><span class="chordrun">Am</span><span class="lyric"> </span></span>`
+	if !strings.Contains(res, expected) {
+		t.Errorf("Expected:\n%s\nin:\n%s", expected, res)
+	}
+}
+
+func TestHTMLTransposedChords(t *testing.T) {
+	content := parse(t, "C#   F\nWords here\n")
+	content.TransposeUpOneStep()
+	res := HTML(content, HTMLOptions{})
+
+	for _, expected := range []string{
+		`<span class="chordrun">D</span><span class="lyric">Words</span>`,
+		`<span class="chordrun">F#</span><span class="lyric"> here</span>`,
+	} {
+		if !strings.Contains(res, expected) {
+			t.Errorf("Expected:\n%s\nin:\n%s", expected, res)
+		}
+	}
+}
+
+func TestHTMLChordOnlyAndLyricOnlyLines(t *testing.T) {
+	content := parse(t, "[Intro]\nC   G\n\nJust <words> & more\n")
+	res := HTML(content, HTMLOptions{})
+
+	for _, expected := range []string{
+		`<div class="line chords"><span class="chordrun">C</span>   <span class="chordrun">G</span></div>`,
+		`<p class="line lyrics">Just &lt;words&gt; &amp; more</p>`,
+	} {
+		if !strings.Contains(res, expected) {
+			t.Errorf("Expected:\n%s\nin:\n%s", expected, res)
+		}
+	}
+
+	if strings.Count(res, `<div class="stanza">`) != 2 {
+		t.Errorf("Expected 2 stanzas, got:\n%s", res)
+	}
+}
+
+func TestHTMLStandalone(t *testing.T) {
+	content := parse(t, song)
+	fragment := HTML(content, HTMLOptions{Title: "Song", Key: "C"})
+	page := HTML(content, HTMLOptions{Title: "Song", Key: "C", Standalone: true})
+
+	if strings.Contains(fragment, "<style>") || !strings.HasPrefix(fragment, "<article") {
+		t.Errorf("Expected a fragment without a style sheet, got:\n%s", fragment)
+	}
+
+	if !strings.HasPrefix(page, "<!DOCTYPE html>") || !strings.Contains(page, HTMLStyle) || !strings.Contains(page, fragment) {
+		t.Errorf("Expected a page wrapping the fragment with its style sheet, got:\n%s", page)
+	}
+
+	if !strings.Contains(fragment, `<header><h1>Song</h1><p class="key">Key: C</p></header>`) {
+		t.Errorf("Expected a header with the title and key, got:\n%s", fragment)
+	}
+}
+
+func TestHTMLAnnotations(t *testing.T) {
+	content := parse(t, "[Intro]\nC  (hold)  G\n\n[Verse]\nG   D  *riff*\nI walked along\n")
+	res := HTML(content, HTMLOptions{})
+
+	for _, expected := range []string{
+		`<div class="line chords"><span class="chordrun">C</span>  <span class="annotationrun">(hold)</span>  <span class="chordrun">G</span></div>`,
+		`<span class="chordrun">D <span class="annotationrun">*riff*</span></span>`,
+	} {
+		if !strings.Contains(res, expected) {
+			t.Errorf("Expected:\n%s\nin:\n%s", expected, res)
+		}
+	}
+}
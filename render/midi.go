@@ -0,0 +1,197 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/bits"
+	"sort"
+
+	"wails-lead-sheet/parser"
+)
+
+const midiTicksPerQuarter = 480
+const defaultTempo = 100
+
+const midiChordChannel = 0
+const midiBassChannel = 1
+const midiClickChannel = 9
+
+const midiChordProgram = 4 // electric piano
+const midiBassProgram = 33 // fingered bass
+
+const midiClickAccent = 76 // high wood block
+const midiClickBeat = 77   // low wood block
+
+// MIDIOptions control the backing track. The tempo is in beats per minute;
+// 0 takes it from the song's metadata. Each chord lasts BarsPerChord bars
+// unless the chart gives its beats.
+type MIDIOptions struct {
+	Tempo        int
+	BarsPerChord float64
+	Click        bool
+}
+
+func DefaultMIDIOptions() MIDIOptions {
+	return MIDIOptions{BarsPerChord: 1, Click: true}
+}
+
+// songTempo returns the tempo to play the song at: the one given, or the
+// one in its metadata, or a default
+func songTempo(content parser.ParsedContent, tempo int) int {
+	if tempo <= 0 {
+		tempo = content.Metadata.BPM()
+	}
+	if tempo <= 0 {
+		tempo = defaultTempo
+	}
+
+	return tempo
+}
+
+// chordBeats returns how many beats a chord is played for
+func chordBeats(chord parser.ProgressionChord, barsPerChord float64, beatsPerBar int) float64 {
+	if chord.Beats > 0 {
+		return chord.Beats
+	}
+
+	if barsPerChord <= 0 {
+		barsPerChord = 1
+	}
+
+	return barsPerChord * float64(beatsPerBar)
+}
+
+type midiEvent struct {
+	tick int
+	data []byte
+}
+
+// midiTrack collects events at absolute times, and writes them out in
+// order with the delta times SMF wants
+type midiTrack struct {
+	events []midiEvent
+}
+
+func (t *midiTrack) add(tick int, data ...byte) {
+	t.events = append(t.events, midiEvent{tick: tick, data: data})
+}
+
+func (t *midiTrack) meta(tick int, typ byte, data []byte) {
+	t.add(tick, append(append([]byte{0xff, typ}, midiVarLen(len(data))...), data...)...)
+}
+
+// note adds a note on and its note off
+func (t *midiTrack) note(channel byte, key int, velocity byte, start int, length int) {
+	if key < 0 || key > 127 || length <= 0 {
+		return
+	}
+
+	t.add(start, 0x90|channel, byte(key), velocity)
+	t.add(start+length, 0x80|channel, byte(key), 0)
+}
+
+func midiVarLen(value int) []byte {
+	res := []byte{byte(value & 0x7f)}
+	for value >>= 7; value > 0; value >>= 7 {
+		res = append([]byte{byte(value&0x7f) | 0x80}, res...)
+	}
+
+	return res
+}
+
+// bytes returns the track chunk. Note offs sort before note ons at the same
+// time, so a note repeated straight away isn't cut short.
+func (t *midiTrack) bytes(end int) []byte {
+	sort.SliceStable(t.events, func(i, j int) bool {
+		if t.events[i].tick != t.events[j].tick {
+			return t.events[i].tick < t.events[j].tick
+		}
+
+		return t.events[i].data[0]&0xf0 == 0x80 && t.events[j].data[0]&0xf0 != 0x80
+	})
+
+	var body bytes.Buffer
+	last := 0
+	for _, event := range t.events {
+		body.Write(midiVarLen(event.tick - last))
+		body.Write(event.data)
+		last = event.tick
+	}
+
+	body.Write(midiVarLen(max(0, end-last)))
+	body.Write([]byte{0xff, 0x2f, 0x00})
+
+	var res bytes.Buffer
+	res.WriteString("MTrk")
+	_ = binary.Write(&res, binary.BigEndian, uint32(body.Len()))
+	res.Write(body.Bytes())
+
+	return res.Bytes()
+}
+
+func midiTrackName(name string) *midiTrack {
+	track := &midiTrack{}
+	track.meta(0, 0x03, []byte(name))
+	return track
+}
+
+// WriteMIDI writes the song's chords, in the order of its arrangement, as
+// a Standard MIDI File with block chords, a bass line on the chord's bass
+// note, and optionally a click on every beat
+func WriteMIDI(out io.Writer, content parser.ParsedContent, options MIDIOptions) error {
+	tempo := songTempo(content, options.Tempo)
+
+	beats, beatType := content.Metadata.Meter()
+	ticksPerBeat := midiTicksPerQuarter * 4 / beatType
+	microsecondsPerQuarter := 60000000 * beatType / 4 / tempo
+
+	conductor := midiTrackName(content.Metadata.Title)
+	conductor.meta(0, 0x51, []byte{byte(microsecondsPerQuarter >> 16), byte(microsecondsPerQuarter >> 8), byte(microsecondsPerQuarter)})
+	conductor.meta(0, 0x58, []byte{byte(beats), byte(bits.TrailingZeros(uint(beatType))), 24, 8})
+
+	chords := midiTrackName("Chords")
+	chords.add(0, 0xc0|midiChordChannel, midiChordProgram)
+	bass := midiTrackName("Bass")
+	bass.add(0, 0xc0|midiBassChannel, midiBassProgram)
+
+	tick := 0
+	for _, chord := range content.Arrangement() {
+		length := int(math.Round(chordBeats(chord, options.BarsPerChord, beats) * float64(ticksPerBeat)))
+
+		root := chord.Chord.Root()
+		if root >= 0 {
+			for _, interval := range chord.Chord.Quality().Intervals {
+				chords.note(midiChordChannel, 48+root+interval, 80, tick, length)
+			}
+			bass.note(midiBassChannel, 36+chord.Chord.Bass(), 95, tick, length)
+		}
+
+		tick += length
+	}
+
+	tracks := []*midiTrack{conductor, chords, bass}
+	if options.Click {
+		click := midiTrackName("Click")
+		for beat := 0; beat*ticksPerBeat < tick; beat++ {
+			key, velocity := midiClickBeat, byte(90)
+			if beat%beats == 0 {
+				key, velocity = midiClickAccent, byte(110)
+			}
+			click.note(midiClickChannel, key, velocity, beat*ticksPerBeat, ticksPerBeat/4)
+		}
+		tracks = append(tracks, click)
+	}
+
+	var res bytes.Buffer
+	res.WriteString("MThd")
+	_ = binary.Write(&res, binary.BigEndian, uint32(6))
+	_ = binary.Write(&res, binary.BigEndian, []uint16{1, uint16(len(tracks)), midiTicksPerQuarter})
+	for _, track := range tracks {
+		res.Write(track.bytes(tick))
+	}
+
+	_, err := out.Write(res.Bytes())
+	return err
+}
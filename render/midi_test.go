@@ -0,0 +1,136 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+type testMIDIEvent struct {
+	tick int
+	data []byte
+}
+
+// readMIDI splits a Standard MIDI File into its tracks' events, with
+// absolute times
+func readMIDI(t *testing.T, data []byte) [][]testMIDIEvent {
+	t.Helper()
+	if string(data[:4]) != "MThd" || binary.BigEndian.Uint32(data[4:8]) != 6 {
+		t.Fatalf("Expected a MIDI header, got %v", data[:8])
+	}
+
+	count := int(binary.BigEndian.Uint16(data[10:12]))
+	data = data[14:]
+	res := make([][]testMIDIEvent, 0)
+	for range count {
+		if string(data[:4]) != "MTrk" {
+			t.Fatalf("Expected a track, got %v", data[:4])
+		}
+
+		length := int(binary.BigEndian.Uint32(data[4:8]))
+		body := data[8 : 8+length]
+		data = data[8+length:]
+
+		events := make([]testMIDIEvent, 0)
+		tick := 0
+		readVarLen := func(from []byte) (int, int) {
+			value := 0
+			for index, b := range from {
+				value = value<<7 | int(b&0x7f)
+				if b&0x80 == 0 {
+					return value, index + 1
+				}
+			}
+			t.Fatalf("Unterminated length in %v", from)
+			return 0, 0
+		}
+		for len(body) > 0 {
+			delta, read := readVarLen(body)
+			tick += delta
+			body = body[read:]
+			size := 3
+			switch {
+			case body[0] == 0xff:
+				length, read := readVarLen(body[2:])
+				size = 2 + read + length
+			case body[0]&0xf0 == 0xc0:
+				size = 2
+			}
+			events = append(events, testMIDIEvent{tick: tick, data: body[:size]})
+			body = body[size:]
+		}
+		res = append(res, events)
+	}
+
+	return res
+}
+
+func noteOns(events []testMIDIEvent) [][2]int {
+	res := make([][2]int, 0)
+	for _, event := range events {
+		if event.data[0]&0xf0 == 0x90 {
+			res = append(res, [2]int{event.tick, int(event.data[1])})
+		}
+	}
+
+	return res
+}
+
+func TestMIDIBackingTrack(t *testing.T) {
+	content := parse(t, "[Verse]\nC   Am7/G\n[Chorus]\nF\n")
+	content.Metadata.Tempo = "120 bpm"
+	content.Metadata.TimeSignature = "3/4"
+	content.Metadata.Flow = []string{"C", "V"}
+
+	var out bytes.Buffer
+	err := WriteMIDI(&out, content, MIDIOptions{BarsPerChord: 1, Click: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracks := readMIDI(t, out.Bytes())
+	if len(tracks) != 4 {
+		t.Fatalf("Expected 4 tracks, got %d", len(tracks))
+	}
+
+	expectedTempo := []byte{0xff, 0x51, 0x03, 0x07, 0xa1, 0x20}
+	if !reflect.DeepEqual(tracks[0][1].data, expectedTempo) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expectedTempo, tracks[0][1].data)
+	}
+
+	expectedMeter := []byte{0xff, 0x58, 0x04, 3, 2, 24, 8}
+	if !reflect.DeepEqual(tracks[0][2].data, expectedMeter) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expectedMeter, tracks[0][2].data)
+	}
+
+	bar := 3 * midiTicksPerQuarter
+	expectedChords := [][2]int{{0, 53}, {0, 57}, {0, 60}, {bar, 48}, {bar, 52}, {bar, 55}, {2 * bar, 57}, {2 * bar, 60}, {2 * bar, 64}, {2 * bar, 67}}
+	if !reflect.DeepEqual(noteOns(tracks[1]), expectedChords) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expectedChords, noteOns(tracks[1]))
+	}
+
+	expectedBass := [][2]int{{0, 41}, {bar, 36}, {2 * bar, 43}}
+	if !reflect.DeepEqual(noteOns(tracks[2]), expectedBass) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expectedBass, noteOns(tracks[2]))
+	}
+
+	clicks := noteOns(tracks[3])
+	if len(clicks) != 9 || clicks[0][1] != midiClickAccent || clicks[1][1] != midiClickBeat || clicks[3][1] != midiClickAccent {
+		t.Errorf("Expected 9 clicks accented on each bar, got %#v", clicks)
+	}
+
+	end := tracks[1][len(tracks[1])-1]
+	if end.tick != 3*bar || !reflect.DeepEqual(end.data, []byte{0xff, 0x2f, 0x00}) {
+		t.Errorf("Expected the track to end after 3 bars, got %#v", end)
+	}
+}
+
+func TestMIDIVarLen(t *testing.T) {
+	cases := map[int][]byte{0: {0}, 0x7f: {0x7f}, 0x80: {0x81, 0x00}, 0x3fff: {0xff, 0x7f}, 0x200000: {0x81, 0x80, 0x80, 0x00}}
+	for value, expected := range cases {
+		if !reflect.DeepEqual(midiVarLen(value), expected) {
+			t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, midiVarLen(value))
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package render
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"wails-lead-sheet/parser"
+)
+
+// musicXMLDivisions is how many divisions a quarter note is split into
+const musicXMLDivisions = 4
+
+// MusicXMLOptions control the MusicXML output
+type MusicXMLOptions struct {
+	Title  string
+	Artist string
+	Key    string
+}
+
+var majorKeyFifths = map[string]int{
+	"Cb": -7, "Gb": -6, "Db": -5, "Ab": -4, "Eb": -3, "Bb": -2, "F": -1,
+	"C": 0, "G": 1, "D": 2, "A": 3, "E": 4, "B": 5, "F#": 6, "C#": 7,
+}
+
+var minorKeyFifths = map[string]int{
+	"Ab": -7, "Eb": -6, "Bb": -5, "F": -4, "C": -3, "G": -2, "D": -1,
+	"A": 0, "E": 1, "B": 2, "F#": 3, "C#": 4, "G#": 5, "D#": 6, "A#": 7,
+}
+
+// noteValues are the written note values, with dots, and their lengths in
+// divisions, longest first
+var noteValues = []struct {
+	divisions int
+	typ       string
+	dotted    bool
+}{
+	{16, "whole", false},
+	{12, "half", true},
+	{8, "half", false},
+	{6, "quarter", true},
+	{4, "quarter", false},
+	{3, "eighth", true},
+	{2, "eighth", false},
+	{1, "16th", false},
+}
+
+func xmlText(text string) string {
+	var res strings.Builder
+	_ = xml.EscapeText(&res, []byte(text))
+	return res.String()
+}
+
+// keySignature returns the number of fifths and the mode for a key
+func keySignature(key string) (int, string) {
+	chord := parser.MakeChord(key)
+	if chord.Note == "" {
+		return 0, "major"
+	}
+
+	name := chord.Note
+	if chord.Alter() > 0 {
+		name += "#"
+	} else if chord.Alter() < 0 {
+		name += "b"
+	}
+
+	if strings.HasPrefix(chord.Flavor, "m") && !strings.HasPrefix(chord.Flavor, "maj") {
+		return minorKeyFifths[name], "minor"
+	}
+
+	return majorKeyFifths[name], "major"
+}
+
+func writeHarmony(out *strings.Builder, chord parser.ProgressionChord) {
+	out.WriteString("      <harmony>\n")
+	if chord.Chord.Note == "" {
+		out.WriteString("        <root><root-step>C</root-step></root>\n")
+		fmt.Fprintf(out, "        <kind text=\"%s\">none</kind>\n", xmlText(chord.Text))
+		out.WriteString("      </harmony>\n")
+		return
+	}
+
+	fmt.Fprintf(out, "        <root><root-step>%s</root-step>", chord.Chord.Note)
+	if chord.Chord.Alter() != 0 {
+		fmt.Fprintf(out, "<root-alter>%d</root-alter>", chord.Chord.Alter())
+	}
+	out.WriteString("</root>\n")
+
+	quality := chord.Chord.Quality()
+	fmt.Fprintf(out, "        <kind text=\"%s\">%s</kind>\n", xmlText(chord.Chord.Flavor), quality.Kind)
+
+	bass := chord.Chord.BassNote
+	if bass != nil && bass.Note != "" {
+		fmt.Fprintf(out, "        <bass><bass-step>%s</bass-step>", bass.Note)
+		if bass.Alter() != 0 {
+			fmt.Fprintf(out, "<bass-alter>%d</bass-alter>", bass.Alter())
+		}
+		out.WriteString("</bass>\n")
+	}
+
+	for _, degree := range quality.Degrees {
+		fmt.Fprintf(out, "        <degree><degree-value>%d</degree-value><degree-alter>%d</degree-alter><degree-type>%s</degree-type></degree>\n",
+			degree.Value, degree.Alter, degree.Type)
+	}
+
+	out.WriteString("      </harmony>\n")
+}
+
+// writeSlashNotes writes slashes lasting the given number of divisions,
+// tied together where it takes more than one note value. The lyric goes on
+// the first of them.
+func writeSlashNotes(out *strings.Builder, divisions int, tiedFromBefore bool, tiesToAfter bool, lyric string) {
+	lengths := make([]int, 0)
+	for _, value := range noteValues {
+		for divisions >= value.divisions {
+			lengths = append(lengths, value.divisions)
+			divisions -= value.divisions
+		}
+	}
+
+	for index, length := range lengths {
+		tieStart := index < len(lengths)-1 || tiesToAfter
+		tieStop := index > 0 || tiedFromBefore
+
+		out.WriteString("      <note>\n        <pitch><step>B</step><octave>4</octave></pitch>\n")
+		fmt.Fprintf(out, "        <duration>%d</duration>\n", length)
+		if tieStop {
+			out.WriteString("        <tie type=\"stop\"/>\n")
+		}
+		if tieStart {
+			out.WriteString("        <tie type=\"start\"/>\n")
+		}
+		for _, value := range noteValues {
+			if value.divisions == length {
+				fmt.Fprintf(out, "        <type>%s</type>\n", value.typ)
+				if value.dotted {
+					out.WriteString("        <dot/>\n")
+				}
+				break
+			}
+		}
+		out.WriteString("        <notehead>slash</notehead>\n")
+		if tieStart || tieStop {
+			out.WriteString("        <notations>")
+			if tieStop {
+				out.WriteString("<tied type=\"stop\"/>")
+			}
+			if tieStart {
+				out.WriteString("<tied type=\"start\"/>")
+			}
+			out.WriteString("</notations>\n")
+		}
+		if index == 0 && lyric != "" {
+			fmt.Fprintf(out, "        <lyric number=\"1\"><syllabic>single</syllabic><text>%s</text></lyric>\n", xmlText(lyric))
+		}
+		out.WriteString("      </note>\n")
+	}
+}
+
+// MusicXML renders the content's chord progression as a MusicXML lead
+// sheet, with a harmony for each chord over slashes, the lyrics under
+// them, and the sections as rehearsal marks. Each chord lasts a bar unless
+// the chart gives its beats.
+func MusicXML(content parser.ParsedContent, options MusicXMLOptions) string {
+	var out strings.Builder
+	out.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n")
+	out.WriteString(`<!DOCTYPE score-partwise PUBLIC "-//Recordare//DTD MusicXML 4.0 Partwise//EN" "http://www.musicxml.org/dtds/partwise.dtd">` + "\n")
+	out.WriteString("<score-partwise version=\"4.0\">\n")
+	fmt.Fprintf(&out, "  <work><work-title>%s</work-title></work>\n", xmlText(options.Title))
+	out.WriteString("  <identification>\n")
+	if options.Artist != "" {
+		fmt.Fprintf(&out, "    <creator type=\"composer\">%s</creator>\n", xmlText(options.Artist))
+	}
+	out.WriteString("    <encoding><software>wails-lead-sheet</software></encoding>\n  </identification>\n")
+	out.WriteString("  <part-list><score-part id=\"P1\"><part-name>Lead Sheet</part-name></score-part></part-list>\n")
+	out.WriteString("  <part id=\"P1\">\n")
+
+	beats, beatType := content.Metadata.Meter()
+	measureLength := max(1, beats*musicXMLDivisions*4/beatType)
+	fifths, mode := keySignature(options.Key)
+
+	measure := 0
+	used := measureLength
+	startMeasure := func() {
+		if measure > 0 {
+			out.WriteString("    </measure>\n")
+		}
+
+		measure += 1
+		used = 0
+		fmt.Fprintf(&out, "    <measure number=\"%d\">\n", measure)
+		if measure == 1 {
+			out.WriteString("      <attributes>\n")
+			fmt.Fprintf(&out, "        <divisions>%d</divisions>\n", musicXMLDivisions)
+			fmt.Fprintf(&out, "        <key><fifths>%d</fifths><mode>%s</mode></key>\n", fifths, mode)
+			fmt.Fprintf(&out, "        <time><beats>%d</beats><beat-type>%d</beat-type></time>\n", beats, beatType)
+			out.WriteString("        <clef><sign>G</sign><line>2</line></clef>\n")
+			out.WriteString("      </attributes>\n")
+		}
+	}
+
+	progression := content.Progression()
+	for _, chord := range progression {
+		length := measureLength
+		if chord.Beats > 0 {
+			length = max(1, int(math.Round(chord.Beats*musicXMLDivisions*4/float64(beatType))))
+		}
+
+		if used == measureLength {
+			startMeasure()
+		}
+
+		if chord.StartsSection {
+			fmt.Fprintf(&out, "      <direction placement=\"above\"><direction-type><rehearsal>%s</rehearsal></direction-type></direction>\n",
+				xmlText(chord.Section))
+		}
+		writeHarmony(&out, chord)
+
+		first := true
+		for length > 0 {
+			if used == measureLength {
+				startMeasure()
+			}
+
+			piece := min(length, measureLength-used)
+			length -= piece
+			lyric := ""
+			if first {
+				lyric = chord.Lyric
+			}
+			writeSlashNotes(&out, piece, !first, length > 0, lyric)
+			used += piece
+			first = false
+		}
+	}
+
+	if measure == 0 {
+		startMeasure()
+		fmt.Fprintf(&out, "      <note><rest measure=\"yes\"/><duration>%d</duration></note>\n", measureLength)
+		used = measureLength
+	}
+
+	if used < measureLength {
+		fmt.Fprintf(&out, "      <note><rest/><duration>%d</duration></note>\n", measureLength-used)
+	}
+
+	out.WriteString("    </measure>\n  </part>\n</score-partwise>\n")
+
+	return out.String()
+}
+
+// WriteMusicXML renders the content as MusicXML to the writer
+func WriteMusicXML(out io.Writer, content parser.ParsedContent, options MusicXMLOptions) error {
+	_, err := io.WriteString(out, MusicXML(content, options))
+	return err
+}
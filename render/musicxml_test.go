@@ -0,0 +1,89 @@
+package render
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestMusicXMLHarmonies(t *testing.T) {
+	content := parse(t, "[Verse]\nC#m7   Bb/D Cadd9\nSing a long song\n[Chorus]\nN.C.\n")
+	res := MusicXML(content, MusicXMLOptions{Title: "Song", Key: "E"})
+
+	expected := []string{
+		"<root><root-step>C</root-step><root-alter>1</root-alter></root>\n        <kind text=\"m7\">minor-seventh</kind>",
+		"<root><root-step>B</root-step><root-alter>-1</root-alter></root>\n        <kind text=\"\">major</kind>\n" +
+			"        <bass><bass-step>D</bass-step></bass>",
+		"<kind text=\"add9\">major</kind>\n        <degree><degree-value>9</degree-value><degree-alter>0</degree-alter><degree-type>add</degree-type></degree>",
+		"<kind text=\"N.C.\">none</kind>",
+		"<rehearsal>Verse</rehearsal>",
+		"<rehearsal>Chorus</rehearsal>",
+		"<lyric number=\"1\"><syllabic>single</syllabic><text>Sing a</text></lyric>",
+		"<text>song</text>",
+		"<key><fifths>4</fifths><mode>major</mode></key>",
+		"<measure number=\"4\">",
+	}
+	for _, text := range expected {
+		if !strings.Contains(res, text) {
+			t.Errorf("Expected:\n%s\nin:\n%s", text, res)
+		}
+	}
+
+	if strings.Contains(res, "<measure number=\"5\">") {
+		t.Errorf("Expected one bar per chord, got:\n%s", res)
+	}
+
+	err := xml.Unmarshal([]byte(res), new(struct{}))
+	if err != nil {
+		t.Errorf("Expected well formed XML, got %v", err)
+	}
+}
+
+func TestMusicXMLFollowsTimeSignature(t *testing.T) {
+	content := parse(t, "C   G\n")
+	content.Metadata.TimeSignature = "3/4"
+	res := MusicXML(content, MusicXMLOptions{Key: "Em"})
+
+	expected := []string{
+		"<time><beats>3</beats><beat-type>4</beat-type></time>",
+		"<key><fifths>1</fifths><mode>minor</mode></key>",
+		"<duration>12</duration>\n        <type>half</type>\n        <dot/>",
+	}
+	for _, text := range expected {
+		if !strings.Contains(res, text) {
+			t.Errorf("Expected:\n%s\nin:\n%s", text, res)
+		}
+	}
+}
+
+func TestMusicXMLWithoutChords(t *testing.T) {
+	content := parse(t, "Just some words\n")
+	res := MusicXML(content, MusicXMLOptions{})
+
+	if !strings.Contains(res, "<rest measure=\"yes\"/><duration>16</duration>") {
+		t.Errorf("Expected a bar's rest, got:\n%s", res)
+	}
+}
+
+func TestMusicXMLFollowsBeats(t *testing.T) {
+	content := parse(t, "C / / G / / / F\n")
+	res := MusicXML(content, MusicXMLOptions{})
+
+	expected := []string{
+		"<duration>12</duration>\n        <type>half</type>\n        <dot/>",
+		"<duration>4</duration>\n        <tie type=\"start\"/>\n        <type>quarter</type>",
+		"<measure number=\"2\">",
+		"<duration>12</duration>\n        <tie type=\"stop\"/>\n        <type>half</type>\n        <dot/>",
+		"<root><root-step>F</root-step></root>\n        <kind text=\"\">major</kind>\n      </harmony>\n      <note>\n" +
+			"        <pitch><step>B</step><octave>4</octave></pitch>\n        <duration>4</duration>\n        <type>quarter</type>",
+	}
+	for _, text := range expected {
+		if !strings.Contains(res, text) {
+			t.Errorf("Expected:\n%s\nin:\n%s", text, res)
+		}
+	}
+
+	if strings.Contains(res, "<measure number=\"3\">") {
+		t.Errorf("Expected two bars, got:\n%s", res)
+	}
+}
@@ -0,0 +1,168 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"wails-lead-sheet/parser"
+)
+
+// NashvilleOptions control the Nashville number chart. The key is the key
+// the chords are numbered in; if it isn't given the song's own is used,
+// or failing that its first chord.
+type NashvilleOptions struct {
+	Title      string
+	Key        string
+	MinorDash  bool
+	BarsPerRow int
+}
+
+func DefaultNashvilleOptions() NashvilleOptions {
+	return NashvilleOptions{MinorDash: true, BarsPerRow: 8}
+}
+
+const nashvilleBarsPerGroup = 4
+
+type chartSegment struct {
+	label string
+	beats int
+}
+
+// chartBar writes a bar of the chart. A bar with one chord is just its
+// number. A split bar is underlined, as _1 4_, with dots for the extra
+// beats when the chords don't share it equally, as _1.. 4_.
+func chartBar(bar []string, previous string) (string, string) {
+	segments := make([]chartSegment, 0)
+	for _, slot := range bar {
+		switch {
+		case slot != gridHold:
+			segments = append(segments, chartSegment{label: slot, beats: 1})
+		case len(segments) == 0:
+			segments = append(segments, chartSegment{label: previous, beats: 1})
+		default:
+			segments[len(segments)-1].beats += 1
+		}
+	}
+
+	last := segments[len(segments)-1].label
+	if len(segments) == 1 {
+		return last, last
+	}
+
+	even := true
+	for _, segment := range segments {
+		even = even && segment.beats == segments[0].beats
+	}
+
+	parts := make([]string, len(segments))
+	for index, segment := range segments {
+		parts[index] = segment.label
+		if !even {
+			parts[index] += strings.Repeat(".", segment.beats-1)
+		}
+	}
+
+	return "_" + strings.Join(parts, " ") + "_", last
+}
+
+// NashvilleChart writes the song as a compact Nashville number chart, with
+// no lyrics and a line of bars for each section, as
+// V1: 1 1 4 1 | 5 5 1 1
+// Pushed chords are written ^4, and diamonds <1>.
+func NashvilleChart(content parser.ParsedContent, options NashvilleOptions) string {
+	key := parser.MakeChord(options.Key)
+	if key.Root() < 0 {
+		key = parser.MakeChord(content.Metadata.Key)
+	}
+
+	progression := content.Progression()
+	if key.Root() < 0 && len(progression) > 0 {
+		key = progression[0].Chord
+		key.BassNote = nil
+	}
+
+	label := func(chord parser.ProgressionChord) string {
+		res := chord.Text
+		if chord.Chord.Root() >= 0 && key.Root() >= 0 {
+			res = chord.Chord.NashvilleNumber(key, options.MinorDash)
+		}
+
+		if chord.Push {
+			res = "^" + res
+		}
+
+		if chord.Diamond {
+			res = "<" + res + ">"
+		}
+
+		return res
+	}
+
+	var out strings.Builder
+	if options.Title != "" {
+		out.WriteString(options.Title + "\n")
+	}
+
+	heading := make([]string, 0)
+	if key.Root() >= 0 {
+		heading = append(heading, "Key: "+key.String())
+	}
+
+	if content.Metadata.BPM() > 0 {
+		heading = append(heading, fmt.Sprintf("Tempo: %d", content.Metadata.BPM()))
+	}
+
+	beats, beatType := content.Metadata.Meter()
+	heading = append(heading, fmt.Sprintf("Time: %d/%d", beats, beatType))
+	out.WriteString(strings.Join(heading, "  ") + "\n\n")
+
+	sections := gridSections(content, label)
+	labelWidth := 0
+	for _, section := range sections {
+		labelWidth = max(labelWidth, len(parser.SectionAbbreviation(section.name)))
+	}
+
+	barsPerRow := options.BarsPerRow
+	if barsPerRow < 1 {
+		barsPerRow = DefaultNashvilleOptions().BarsPerRow
+	}
+
+	for _, section := range sections {
+		name := strings.ToUpper(parser.SectionAbbreviation(section.name))
+		prefix := ""
+		if labelWidth > 0 {
+			prefix = fmt.Sprintf("%-*s ", labelWidth+1, name+":")
+			if name == "" {
+				prefix = strings.Repeat(" ", labelWidth+2)
+			}
+		}
+
+		previous := ""
+		for rowIndex, row := range gridRows(section.bars, barsPerRow) {
+			if rowIndex > 0 {
+				prefix = strings.Repeat(" ", len(prefix))
+			}
+
+			cells := make([]string, 0, len(row))
+			for index, bar := range row {
+				var cell string
+				cell, previous = chartBar(bar, previous)
+				if index > 0 && index%nashvilleBarsPerGroup == 0 {
+					cells = append(cells, "|")
+				}
+				cells = append(cells, cell)
+			}
+
+			out.WriteString(prefix + strings.Join(cells, " ") + "\n")
+		}
+	}
+
+	return out.String()
+}
+
+// WriteNashvilleChart writes the song as a Nashville number chart to the writer
+func WriteNashvilleChart(out io.Writer, content parser.ParsedContent, options NashvilleOptions) error {
+	_, err := io.WriteString(out, NashvilleChart(content, options))
+	return err
+}
@@ -0,0 +1,35 @@
+package render
+
+import "testing"
+
+func TestNashvilleChart(t *testing.T) {
+	content := parse(t, "[Verse 1]\nD  D  G  D\nSome words to sing\nA  A  D  D\n[Chorus]\n| G / A / | Bm / / A | <D> | ^G |\n[Verse 2]\nF#m7/C#\n")
+	content.Metadata.Tempo = "120"
+	res := NashvilleChart(content, NashvilleOptions{Title: "Song", Key: "D", MinorDash: true, BarsPerRow: 8})
+
+	expected := `Song
+Key: D  Tempo: 120  Time: 4/4
+
+V1: 1 1 4 1 | 5 5 1 1
+C:  _4 5_ _6-.. 5_ <1> ^4
+V2: 3-7/7
+`
+	if res != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, res)
+	}
+}
+
+func TestNashvilleChartHeldChordsAndKey(t *testing.T) {
+	content := parse(t, "Am . . . . . G .\n")
+	content.Metadata.TimeSignature = "3/4"
+	res := NashvilleChart(content, NashvilleOptions{MinorDash: false, BarsPerRow: 2})
+
+	expected := `Key: Am  Time: 3/4
+
+1m 1m
+b7
+`
+	if res != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, res)
+	}
+}
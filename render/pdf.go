@@ -0,0 +1,337 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"wails-lead-sheet/parser"
+)
+
+const pdfMargin = 36.0
+const pdfGutter = 18.0
+const pdfTitleSize = 14.0
+const pdfFooterSize = 8.0
+
+var pageSizes = map[string][2]float64{
+	"letter": {612, 792},
+	"a4":     {595.28, 841.89},
+}
+
+// PDFOptions control how a lead sheet is laid out on the page
+type PDFOptions struct {
+	Title    string
+	Key      string
+	PageSize string
+	Columns  int
+	FontSize float64
+}
+
+func DefaultPDFOptions() PDFOptions {
+	return PDFOptions{PageSize: "letter", Columns: 2, FontSize: 10}
+}
+
+type rowStyle int
+
+const (
+	lyricRow rowStyle = iota
+	chordRow
+	sectionRow
+)
+
+type pdfRow struct {
+	style rowStyle
+	text  string
+}
+
+// pdfPage holds the rows for each column of one page
+type pdfPage [][]pdfRow
+
+func styleFor(line parser.Line) rowStyle {
+	switch line.Type {
+	case parser.LineTypes.CHORDS:
+		return chordRow
+	case parser.LineTypes.SECTION:
+		return sectionRow
+	}
+
+	return lyricRow
+}
+
+// breakColumn finds where to break lines which are wider than the width.
+// All the texts are broken at the same column, so chords stay over their
+// lyrics; a column where none of them is in the middle of a word is
+// preferred.
+func breakColumn(texts [][]rune, width int) int {
+	for col := width; col > width/2; col-- {
+		clean := true
+		for _, text := range texts {
+			if col < len(text) && text[col-1] != ' ' && text[col] != ' ' {
+				clean = false
+				break
+			}
+		}
+
+		if clean {
+			return col
+		}
+	}
+
+	return width
+}
+
+// wrapRows breaks the given lines (a chord line and its lyrics, or a single
+// line) into rows no wider than the width
+func wrapRows(lines []parser.Line, width int) []pdfRow {
+	texts := make([][]rune, len(lines))
+	longest := 0
+	for index, line := range lines {
+		texts[index] = []rune(line.String())
+		longest = max(longest, len(texts[index]))
+	}
+
+	res := make([]pdfRow, 0)
+	for longest > 0 {
+		col := longest
+		if longest > width {
+			col = breakColumn(texts, width)
+		}
+
+		for index, line := range lines {
+			piece := texts[index][:min(col, len(texts[index]))]
+			res = append(res, pdfRow{style: styleFor(line), text: strings.TrimRight(string(piece), " ")})
+			texts[index] = texts[index][min(col, len(texts[index])):]
+		}
+
+		indent := longest
+		longest = 0
+		for _, text := range texts {
+			if len(text) > 0 {
+				leading := len(text) - len(strings.TrimLeft(string(text), " "))
+				indent = min(indent, leading)
+			}
+		}
+
+		for index := range texts {
+			texts[index] = texts[index][min(indent, len(texts[index])):]
+			longest = max(longest, len(texts[index]))
+		}
+	}
+
+	return res
+}
+
+// paragraphs splits the content at empty lines, and wraps each paragraph
+// into rows
+func paragraphs(content parser.ParsedContent, width int) [][]pdfRow {
+	res := make([][]pdfRow, 0)
+	current := make([]pdfRow, 0)
+	for index := 0; index < len(content.Lines); index++ {
+		line := content.Lines[index]
+		if line.Type == parser.LineTypes.EMPTY {
+			if len(current) > 0 {
+				res = append(res, current)
+				current = make([]pdfRow, 0)
+			}
+			continue
+		}
+
+		group := []parser.Line{line}
+		if line.Type == parser.LineTypes.CHORDS && index+1 < len(content.Lines) &&
+			content.Lines[index+1].Type == parser.LineTypes.LYRICS {
+			group = append(group, content.Lines[index+1])
+			index += 1
+		}
+
+		current = append(current, wrapRows(group, width)...)
+	}
+
+	if len(current) > 0 {
+		res = append(res, current)
+	}
+
+	return res
+}
+
+// keepsWithNext reports whether a row shouldn't be left at the bottom of a
+// column without the row after it
+func keepsWithNext(rows []pdfRow, index int) bool {
+	if index+1 >= len(rows) {
+		return false
+	}
+
+	return rows[index].style == sectionRow ||
+		(rows[index].style == chordRow && rows[index+1].style == lyricRow)
+}
+
+// paginate flows the paragraphs down the columns of as many pages as are
+// needed. A paragraph is kept in one column if it fits in one.
+func paginate(paras [][]pdfRow, columns int, rowsPerColumn int) []pdfPage {
+	pages := []pdfPage{make(pdfPage, columns)}
+	column := 0
+	used := 0
+	nextColumn := func() {
+		column += 1
+		used = 0
+		if column == columns {
+			pages = append(pages, make(pdfPage, columns))
+			column = 0
+		}
+	}
+	place := func(rows []pdfRow) {
+		page := pages[len(pages)-1]
+		page[column] = append(page[column], rows...)
+		used += len(rows)
+	}
+
+	for _, para := range paras {
+		gap := 0
+		if used > 0 {
+			gap = 1
+		}
+
+		if used+gap+len(para) <= rowsPerColumn {
+			if gap > 0 {
+				place([]pdfRow{{style: lyricRow}})
+			}
+			place(para)
+			continue
+		}
+
+		if len(para) <= rowsPerColumn {
+			nextColumn()
+			place(para)
+			continue
+		}
+
+		if used > 0 {
+			nextColumn()
+		}
+
+		remaining := para
+		for len(remaining) > 0 {
+			count := min(rowsPerColumn-used, len(remaining))
+			for count > 1 && count < len(remaining) && keepsWithNext(remaining, count-1) {
+				count -= 1
+			}
+
+			place(remaining[:count])
+			remaining = remaining[count:]
+			if len(remaining) > 0 {
+				nextColumn()
+			}
+		}
+	}
+
+	return pages
+}
+
+func pageStream(page pdfPage, number int, total int, options PDFOptions, regular pdfFont, width float64, height float64) string {
+	var res strings.Builder
+	lineHeight := options.FontSize * 1.2
+	top := height - pdfMargin
+
+	fmt.Fprintf(&res, "BT /F2 %.2f Tf 0 g %.2f %.2f Td %s Tj ET\n",
+		pdfTitleSize, pdfMargin, top-pdfTitleSize, pdfString(options.Title))
+	if options.Key != "" {
+		key := "Key: " + options.Key
+		fmt.Fprintf(&res, "BT /F1 %.2f Tf 0 g %.2f %.2f Td %s Tj ET\n",
+			pdfTitleSize, width-pdfMargin-regular.textWidth(key, pdfTitleSize), top-pdfTitleSize, pdfString(key))
+	}
+
+	ruleY := top - pdfTitleSize*1.6
+	fmt.Fprintf(&res, "0.6 G 0.5 w %.2f %.2f m %.2f %.2f l S\n", pdfMargin, ruleY, width-pdfMargin, ruleY)
+
+	columnWidth := (width - 2*pdfMargin - float64(options.Columns-1)*pdfGutter) / float64(options.Columns)
+	for column, rows := range page {
+		x := pdfMargin + float64(column)*(columnWidth+pdfGutter)
+		y := ruleY - lineHeight
+		for _, row := range rows {
+			if row.text != "" {
+				switch row.style {
+				case chordRow:
+					fmt.Fprintf(&res, "BT /F2 %.2f Tf 0.1 0.25 0.6 rg %.2f %.2f Td %s Tj ET\n", options.FontSize, x, y, pdfString(row.text))
+				case sectionRow:
+					fmt.Fprintf(&res, "BT /F2 %.2f Tf 0 g %.2f %.2f Td %s Tj ET\n", options.FontSize, x, y, pdfString(row.text))
+				default:
+					fmt.Fprintf(&res, "BT /F1 %.2f Tf 0 g %.2f %.2f Td %s Tj ET\n", options.FontSize, x, y, pdfString(row.text))
+				}
+			}
+			y -= lineHeight
+		}
+	}
+
+	footerY := pdfMargin - pdfFooterSize
+	pageText := fmt.Sprintf("Page %d of %d", number, total)
+	fmt.Fprintf(&res, "BT /F1 %.2f Tf 0.4 g %.2f %.2f Td %s Tj ET\n", pdfFooterSize, pdfMargin, footerY, pdfString(options.Title))
+	fmt.Fprintf(&res, "BT /F1 %.2f Tf 0.4 g %.2f %.2f Td %s Tj ET\n",
+		pdfFooterSize, width-pdfMargin-regular.textWidth(pageText, pdfFooterSize), footerY, pdfString(pageText))
+
+	return res.String()
+}
+
+// WritePDF lays out the content as a lead sheet and writes it as a PDF
+func WritePDF(out io.Writer, content parser.ParsedContent, options PDFOptions) error {
+	size, found := pageSizes[options.PageSize]
+	if !found {
+		return fmt.Errorf("unknown page size %#v", options.PageSize)
+	}
+
+	if options.Columns < 1 {
+		options.Columns = 1
+	}
+
+	if options.FontSize <= 0 {
+		options.FontSize = DefaultPDFOptions().FontSize
+	}
+
+	regular, bold, err := loadFonts()
+	if err != nil {
+		return err
+	}
+
+	width, height := size[0], size[1]
+	lineHeight := options.FontSize * 1.2
+	columnWidth := (width - 2*pdfMargin - float64(options.Columns-1)*pdfGutter) / float64(options.Columns)
+	charsPerLine := int(math.Floor(columnWidth / regular.textWidth("M", options.FontSize)))
+	bodyHeight := height - 2*pdfMargin - pdfTitleSize*1.6 - pdfFooterSize*2
+	rowsPerColumn := int(math.Floor(bodyHeight / lineHeight))
+	if charsPerLine < 10 || rowsPerColumn < 4 {
+		return fmt.Errorf("font size %.1f is too large for the page", options.FontSize)
+	}
+
+	pages := paginate(paragraphs(content, charsPerLine), options.Columns, rowsPerColumn)
+
+	w := &pdfWriter{}
+	catalog := w.reserve()
+	pagesObject := w.reserve()
+	regularFont, err := regular.embed(w)
+	if err != nil {
+		return err
+	}
+
+	boldFont, err := bold.embed(w)
+	if err != nil {
+		return err
+	}
+
+	resources := w.add(fmt.Sprintf("<< /Font << /F1 %d 0 R /F2 %d 0 R >> >>", regularFont, boldFont))
+	kids := make([]string, len(pages))
+	for index, page := range pages {
+		stream, err := w.addStream([]byte(pageStream(page, index+1, len(pages), options, regular, width, height)), "")
+		if err != nil {
+			return err
+		}
+
+		pageObject := w.add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources %d 0 R /Contents %d 0 R >>",
+			pagesObject, width, height, resources, stream))
+		kids[index] = fmt.Sprintf("%d 0 R", pageObject)
+	}
+
+	w.set(pagesObject, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	w.set(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObject))
+	info := w.add(fmt.Sprintf("<< /Title %s /Producer (wails-lead-sheet) >>", pdfString(options.Title)))
+
+	return w.writeTo(out, catalog, info)
+}
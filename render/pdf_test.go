@@ -0,0 +1,169 @@
+package render
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"wails-lead-sheet/parser"
+)
+
+const song = `[Verse]
+C       G       Am
+These are the lyrics
+
+[Chorus]
+F       C
+Sing it loud
+`
+
+func parse(t *testing.T, text string) parser.ParsedContent {
+	t.Helper()
+	content := parser.ParsedContent{}
+	err := content.ParseContent(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return content
+}
+
+func TestPDFString(t *testing.T) {
+	for _, check := range []struct {
+		source   string
+		expected string
+	}{
+		{"plain", "(plain)"},
+		{"(x2) \\", "(\\(x2\\) \\\\)"},
+		{"café", "(caf\\351)"},
+		{"“hi”", "(\\223hi\\224)"},
+		{"日本", "(??)"},
+	} {
+		if pdfString(check.source) != check.expected {
+			t.Errorf("Expected %s for %#v, got %s", check.expected, check.source, pdfString(check.source))
+		}
+	}
+}
+
+func TestWrapRowsKeepsChordsOverLyrics(t *testing.T) {
+	content := parse(t, "C          G          Am\nThe first words and the second words\n")
+	rows := wrapRows(content.Lines, 20)
+	expected := []pdfRow{
+		{style: chordRow, text: "C          G"},
+		{style: lyricRow, text: "The first words and"},
+		{style: chordRow, text: "  Am"},
+		{style: lyricRow, text: "the second words"},
+	}
+
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, rows)
+	}
+}
+
+func TestParagraphs(t *testing.T) {
+	paras := paragraphs(parse(t, song), 40)
+	if len(paras) != 2 {
+		t.Fatalf("Expected 2 paragraphs, got %d", len(paras))
+	}
+
+	if len(paras[0]) != 3 || paras[0][0].style != sectionRow || paras[0][1].style != chordRow || paras[0][2].style != lyricRow {
+		t.Errorf("Unexpected first paragraph %#v", paras[0])
+	}
+}
+
+func TestPaginateKeepsParagraphsTogether(t *testing.T) {
+	para := []pdfRow{{style: sectionRow, text: "[A]"}, {style: chordRow, text: "C"}, {style: lyricRow, text: "words"}}
+	pages := paginate([][]pdfRow{para, para, para, para, para}, 2, 7)
+
+	if len(pages) != 2 {
+		t.Fatalf("Expected 2 pages, got %d", len(pages))
+	}
+
+	if len(pages[0][0]) != 7 || len(pages[0][1]) != 7 || len(pages[1][0]) != 3 || len(pages[1][1]) != 0 {
+		t.Errorf("Unexpected layout %#v", pages)
+	}
+}
+
+func TestPaginateSplitsLongParagraphsBetweenPairs(t *testing.T) {
+	para := make([]pdfRow, 0)
+	for range 4 {
+		para = append(para, pdfRow{style: chordRow, text: "C"}, pdfRow{style: lyricRow, text: "words"})
+	}
+
+	pages := paginate([][]pdfRow{para}, 1, 5)
+	if len(pages) != 2 || len(pages[0][0]) != 4 || len(pages[1][0]) != 4 {
+		t.Errorf("Expected the paragraph split after two pairs, got %#v", pages)
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	var out bytes.Buffer
+	options := DefaultPDFOptions()
+	options.Title = "Test Song"
+	options.Key = "C"
+	err := WritePDF(&out, parse(t, song), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pdf := out.Bytes()
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Errorf("Expected a PDF header and trailer")
+	}
+
+	for _, expected := range []string{"/FontFile2", "/Count 1", "/MediaBox [0 0 612.00 792.00]", "/Title (Test Song)"} {
+		if !bytes.Contains(pdf, []byte(expected)) {
+			t.Errorf("Expected the PDF to contain %s", expected)
+		}
+	}
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	if startxref == nil {
+		t.Fatalf("Expected a startxref entry")
+	}
+
+	offset, _ := strconv.Atoi(string(startxref[1]))
+	if !bytes.HasPrefix(pdf[offset:], []byte("xref\n")) {
+		t.Errorf("Expected startxref to point at the xref table")
+	}
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf, -1)
+	for index, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if !bytes.HasPrefix(pdf[offset:], []byte(strconv.Itoa(index+1)+" 0 obj")) {
+			t.Errorf("Expected xref entry %d to point at its object", index+1)
+		}
+	}
+}
+
+func TestWritePDFManyPages(t *testing.T) {
+	var out bytes.Buffer
+	options := DefaultPDFOptions()
+	options.PageSize = "a4"
+	options.Columns = 1
+	err := WritePDF(&out, parse(t, strings.Repeat(song+"\n", 40)), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(out.Bytes(), []byte("/Count 6")) {
+		t.Errorf("Expected 6 pages, got %s", regexp.MustCompile(`/Count \d+`).Find(out.Bytes()))
+	}
+}
+
+func TestWritePDFBadOptions(t *testing.T) {
+	options := DefaultPDFOptions()
+	options.PageSize = "tabloid"
+	if WritePDF(&bytes.Buffer{}, parse(t, song), options) == nil {
+		t.Errorf("Expected an error for an unknown page size")
+	}
+
+	options = DefaultPDFOptions()
+	options.FontSize = 200
+	if WritePDF(&bytes.Buffer{}, parse(t, song), options) == nil {
+		t.Errorf("Expected an error for a huge font")
+	}
+}
@@ -0,0 +1,104 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// pdfFont is a monospace TrueType font which is embedded whole in the PDF,
+// so the output looks the same everywhere without any installed fonts.
+// All measurements are in thousandths of the font size, as PDF wants.
+type pdfFont struct {
+	name      string
+	ttf       []byte
+	width     int
+	ascent    int
+	descent   int
+	capHeight int
+	bbox      [4]int
+}
+
+func loadFont(name string, ttf []byte) (pdfFont, error) {
+	res := pdfFont{name: name, ttf: ttf}
+	f, err := sfnt.Parse(ttf)
+	if err != nil {
+		return res, err
+	}
+
+	var buf sfnt.Buffer
+	unitsPerEm := int(f.UnitsPerEm())
+	ppem := fixed.I(unitsPerEm)
+	scale := func(v fixed.Int26_6) int {
+		return v.Round() * 1000 / unitsPerEm
+	}
+
+	glyph, err := f.GlyphIndex(&buf, 'M')
+	if err != nil {
+		return res, err
+	}
+
+	advance, err := f.GlyphAdvance(&buf, glyph, ppem, font.HintingNone)
+	if err != nil {
+		return res, err
+	}
+
+	metrics, err := f.Metrics(&buf, ppem, font.HintingNone)
+	if err != nil {
+		return res, err
+	}
+
+	bounds, err := f.Bounds(&buf, ppem, font.HintingNone)
+	if err != nil {
+		return res, err
+	}
+
+	res.width = scale(advance)
+	res.ascent = scale(metrics.Ascent)
+	res.descent = -scale(metrics.Descent)
+	res.capHeight = scale(metrics.CapHeight)
+	res.bbox = [4]int{scale(bounds.Min.X), -scale(bounds.Max.Y), scale(bounds.Max.X), -scale(bounds.Min.Y)}
+
+	return res, nil
+}
+
+func loadFonts() (pdfFont, pdfFont, error) {
+	regular, err := loadFont("GoMono", gomono.TTF)
+	if err != nil {
+		return regular, regular, err
+	}
+
+	bold, err := loadFont("GoMono-Bold", gomonobold.TTF)
+	if err != nil {
+		return regular, bold, err
+	}
+
+	return regular, bold, nil
+}
+
+// textWidth returns the width of the text in points at the given size
+func (f pdfFont) textWidth(text string, size float64) float64 {
+	return float64(len([]rune(text))*f.width) * size / 1000
+}
+
+func (f pdfFont) embed(w *pdfWriter) (int, error) {
+	file, err := w.addStream(f.ttf, fmt.Sprintf("/Length1 %d ", len(f.ttf)))
+	if err != nil {
+		return 0, err
+	}
+
+	descriptor := w.add(fmt.Sprintf("<< /Type /FontDescriptor /FontName %s /Flags 33 /FontBBox [%d %d %d %d] "+
+		"/ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		pdfName(f.name), f.bbox[0], f.bbox[1], f.bbox[2], f.bbox[3], f.ascent, f.descent, f.capHeight, file))
+
+	widths := strings.TrimSpace(strings.Repeat(fmt.Sprintf("%d ", f.width), 224))
+
+	return w.add(fmt.Sprintf("<< /Type /Font /Subtype /TrueType /BaseFont %s /FirstChar 32 /LastChar 255 "+
+		"/Widths [%s] /Encoding /WinAnsiEncoding /FontDescriptor %d 0 R >>",
+		pdfName(f.name), widths, descriptor)), nil
+}
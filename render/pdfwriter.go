@@ -0,0 +1,137 @@
+package render
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// pdfWriter builds a PDF file one object at a time. Objects are numbered
+// from 1 in the order they are reserved, and can be written in any order.
+type pdfWriter struct {
+	objects [][]byte
+}
+
+func (w *pdfWriter) reserve() int {
+	w.objects = append(w.objects, nil)
+	return len(w.objects)
+}
+
+func (w *pdfWriter) set(number int, body string) {
+	w.objects[number-1] = []byte(body)
+}
+
+func (w *pdfWriter) add(body string) int {
+	number := w.reserve()
+	w.set(number, body)
+	return number
+}
+
+// addStream adds a compressed stream object. Extra dictionary entries can
+// be given, and are written before the length and filter.
+func (w *pdfWriter) addStream(data []byte, extra string) (int, error) {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	_, err := zw.Write(data)
+	if err != nil {
+		return 0, err
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	body := fmt.Sprintf("<< %s/Length %d /Filter /FlateDecode >>\nstream\n", extra, compressed.Len())
+	number := w.reserve()
+	w.objects[number-1] = append(append([]byte(body), compressed.Bytes()...), []byte("\nendstream")...)
+
+	return number, nil
+}
+
+func (w *pdfWriter) writeTo(out io.Writer, root int, info int) error {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	offsets := make([]int, len(w.objects))
+	for index, body := range w.objects {
+		if body == nil {
+			return fmt.Errorf("PDF object %d was reserved but never written", index+1)
+		}
+
+		offsets[index] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", index+1)
+		buf.Write(body)
+		buf.WriteString("\nendobj\n")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(w.objects)+1, root, info, xref)
+
+	_, err := out.Write(buf.Bytes())
+	return err
+}
+
+// winAnsiSpecials are the characters which WinAnsiEncoding puts between
+// 0x80 and 0x9f. Everything else it shares with Latin-1.
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// pdfString encodes text as a PDF literal string in WinAnsiEncoding.
+// Characters which can't be encoded become '?'.
+func pdfString(text string) string {
+	var res strings.Builder
+	res.WriteByte('(')
+	for _, ch := range text {
+		var b byte
+		switch {
+		case ch < 0x80 || (ch >= 0xa0 && ch <= 0xff):
+			b = byte(ch)
+		default:
+			special, found := winAnsiSpecials[ch]
+			if found {
+				b = special
+			} else {
+				b = '?'
+			}
+		}
+
+		switch {
+		case b == '(' || b == ')' || b == '\\':
+			res.WriteByte('\\')
+			res.WriteByte(b)
+		case b < 0x20 || b >= 0x7f:
+			fmt.Fprintf(&res, "\\%03o", b)
+		default:
+			res.WriteByte(b)
+		}
+	}
+	res.WriteByte(')')
+
+	return res.String()
+}
+
+// pdfName makes a PDF name out of text, dropping anything which isn't a
+// plain letter or digit
+func pdfName(text string) string {
+	res := ""
+	for _, ch := range text {
+		if (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') || ch == '-' {
+			res += string(ch)
+		}
+	}
+
+	return "/" + res
+}
@@ -0,0 +1,22 @@
+package render
+
+import (
+	"encoding/json"
+	"io"
+
+	"wails-lead-sheet/parser"
+)
+
+// WriteStatistics writes a report of the song's statistics to the writer,
+// as text or, if asJSON is set, as indented JSON
+func WriteStatistics(out io.Writer, statistics parser.Statistics, asJSON bool) error {
+	if !asJSON {
+		_, err := io.WriteString(out, statistics.String())
+		return err
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(statistics)
+}
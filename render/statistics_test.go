@@ -0,0 +1,57 @@
+package render
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"wails-lead-sheet/parser"
+)
+
+func TestWriteStatistics(t *testing.T) {
+	content := parser.ParsedContent{}
+	err := content.ParseContent(filterSong)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statistics := content.Statistics()
+
+	var text strings.Builder
+	err = WriteStatistics(&text, statistics, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Key:            C\n" +
+		"Keys touched:   G, C\n" +
+		"Sections:       3\n" +
+		"Lines:          16 (7 chords, 6 lyrics)\n" +
+		"Length:         about 14 bars\n" +
+		"Chords:         C G Am F\n" +
+		"Played:         G ×6, F ×4, C ×3, Am ×1\n" +
+		"Barre chords:   F\n" +
+		"Progressions:\n" +
+		"  5-4-5-4 (G F G F) ×2\n" +
+		"  4-5-4-5 (F G F G) ×2\n"
+	if text.String() != expected {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, text.String())
+	}
+
+	var data strings.Builder
+	err = WriteStatistics(&data, statistics, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res parser.Statistics
+	err = json.Unmarshal([]byte(data.String()), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(res, statistics) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", statistics, res)
+	}
+}
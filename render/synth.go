@@ -0,0 +1,194 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"wails-lead-sheet/parser"
+)
+
+// VoiceSine plays the chords as pure tones
+const VoiceSine = "sine"
+
+// VoiceSaw plays the chords with a bright sawtooth wave
+const VoiceSaw = "saw"
+
+// VoicePluck plays the chords as plucked strings which die away
+const VoicePluck = "pluck"
+
+const synthAttack = 0.01
+const synthDecay = 0.12
+const synthSustain = 0.7
+const synthRelease = 0.15
+const synthPeak = 0.8
+
+// WAVOptions control the audio rendering. The tempo and bars per chord work
+// as they do for MIDI, and the voice is one of the Voice constants.
+type WAVOptions struct {
+	Tempo        int
+	BarsPerChord float64
+	Voice        string
+	SampleRate   int
+}
+
+// DefaultWAVOptions returns the options for a plucked backing track at CD quality
+func DefaultWAVOptions() WAVOptions {
+	return WAVOptions{BarsPerChord: 1, Voice: VoicePluck, SampleRate: 44100}
+}
+
+func keyFrequency(key int) float64 {
+	return 440 * math.Pow(2, float64(key-69)/12)
+}
+
+// envelope returns the loudness of a note at the given time, for a note
+// of the given length. It rises quickly, settles to the sustain level, and
+// fades out over the end of the note so it doesn't click.
+func envelope(t float64, length float64) float64 {
+	level := synthSustain
+	switch {
+	case t < synthAttack:
+		level = t / synthAttack
+	case t < synthAttack+synthDecay:
+		level = 1 - (1-synthSustain)*(t-synthAttack)/synthDecay
+	}
+
+	release := min(synthRelease, length/2)
+	if t > length-release {
+		level *= max(0, (length-t)/release)
+	}
+
+	return level
+}
+
+// synthNote adds a note to the samples, starting at the given sample
+func synthNote(samples []float64, start int, count int, key int, gain float64, voice string, sampleRate int) {
+	frequency := keyFrequency(key)
+	length := float64(count) / float64(sampleRate)
+	end := min(start+count, len(samples))
+
+	switch voice {
+	case VoiceSine:
+		for index := start; index < end; index++ {
+			t := float64(index-start) / float64(sampleRate)
+			samples[index] += gain * envelope(t, length) * math.Sin(2*math.Pi*frequency*t)
+		}
+	case VoiceSaw:
+		// A plain sawtooth is harsh, so it goes through a gentle low pass
+		smoothed := 0.0
+		for index := start; index < end; index++ {
+			t := float64(index-start) / float64(sampleRate)
+			phase := math.Mod(frequency*t, 1)
+			smoothed += 0.2 * (2*phase - 1 - smoothed)
+			samples[index] += gain * envelope(t, length) * smoothed
+		}
+	case VoicePluck:
+		// Karplus-Strong: a burst of noise goes round a delay line which
+		// averages neighbouring samples, so it rings and dies away like a string
+		delay := make([]float64, max(2, int(math.Round(float64(sampleRate)/frequency))))
+		seed := uint32(key*7919 + start)
+		for index := range delay {
+			seed = seed*1664525 + 1013904223
+			delay[index] = float64(seed>>8)/float64(1<<23) - 1
+		}
+
+		position := 0
+		for index := start; index < end; index++ {
+			t := float64(index-start) / float64(sampleRate)
+			next := (position + 1) % len(delay)
+			value := delay[position]
+			delay[position] = 0.996 * 0.5 * (value + delay[next])
+			position = next
+			fade := 1.0
+			if t > length-synthRelease {
+				fade = max(0, (length-t)/synthRelease)
+			}
+			samples[index] += gain * fade * value
+		}
+	}
+}
+
+// synthesize renders the song's chords, in the order of its arrangement,
+// as samples between -1 and 1
+func synthesize(content parser.ParsedContent, options WAVOptions) []float64 {
+	tempo := songTempo(content, options.Tempo)
+	beats, _ := content.Metadata.Meter()
+	secondsPerBeat := 60 / float64(tempo)
+
+	type placed struct {
+		chord parser.Chord
+		start int
+		count int
+	}
+	chords := make([]placed, 0)
+	total := 0
+	for _, chord := range content.Arrangement() {
+		count := int(math.Round(chordBeats(chord, options.BarsPerChord, beats) * secondsPerBeat * float64(options.SampleRate)))
+		chords = append(chords, placed{chord: chord.Chord, start: total, count: count})
+		total += count
+	}
+
+	samples := make([]float64, total)
+	for _, chord := range chords {
+		root := chord.chord.Root()
+		if root < 0 {
+			continue
+		}
+
+		intervals := chord.chord.Quality().Intervals
+		for _, interval := range intervals {
+			synthNote(samples, chord.start, chord.count, 48+root+interval, 1/float64(len(intervals)), options.Voice, options.SampleRate)
+		}
+		synthNote(samples, chord.start, chord.count, 36+chord.chord.Bass(), 0.8, options.Voice, options.SampleRate)
+	}
+
+	peak := 0.0
+	for _, sample := range samples {
+		peak = max(peak, math.Abs(sample))
+	}
+
+	if peak > 0 {
+		for index := range samples {
+			samples[index] *= synthPeak / peak
+		}
+	}
+
+	return samples
+}
+
+// WriteWAV renders the song's chords with a simple built in synthesizer,
+// and writes them as a mono 16 bit WAV file
+func WriteWAV(out io.Writer, content parser.ParsedContent, options WAVOptions) error {
+	if options.Voice != VoiceSine && options.Voice != VoiceSaw && options.Voice != VoicePluck {
+		return fmt.Errorf("unknown voice %#v", options.Voice)
+	}
+
+	if options.SampleRate <= 0 {
+		options.SampleRate = DefaultWAVOptions().SampleRate
+	}
+
+	samples := synthesize(content, options)
+
+	var res bytes.Buffer
+	dataSize := uint32(len(samples) * 2)
+	res.WriteString("RIFF")
+	_ = binary.Write(&res, binary.LittleEndian, 36+dataSize)
+	res.WriteString("WAVEfmt ")
+	_ = binary.Write(&res, binary.LittleEndian, []uint32{16})
+	_ = binary.Write(&res, binary.LittleEndian, []uint16{1, 1})
+	_ = binary.Write(&res, binary.LittleEndian, []uint32{uint32(options.SampleRate), uint32(options.SampleRate * 2)})
+	_ = binary.Write(&res, binary.LittleEndian, []uint16{2, 16})
+	res.WriteString("data")
+	_ = binary.Write(&res, binary.LittleEndian, dataSize)
+
+	pcm := make([]int16, len(samples))
+	for index, sample := range samples {
+		pcm[index] = int16(math.Round(max(-1, min(1, sample)) * math.MaxInt16))
+	}
+	_ = binary.Write(&res, binary.LittleEndian, pcm)
+
+	_, err := out.Write(res.Bytes())
+	return err
+}
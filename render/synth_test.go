@@ -0,0 +1,94 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestWAVHeaderAndLength(t *testing.T) {
+	content := parse(t, "C   G7   N.C.\n")
+	content.Metadata.Tempo = "120"
+
+	for _, voice := range []string{VoiceSine, VoiceSaw, VoicePluck} {
+		var out bytes.Buffer
+		err := WriteWAV(&out, content, WAVOptions{BarsPerChord: 1, Voice: voice, SampleRate: 8000})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data := out.Bytes()
+		if string(data[:4]) != "RIFF" || string(data[8:16]) != "WAVEfmt " || string(data[36:40]) != "data" {
+			t.Fatalf("Expected a WAV header, got %q", data[:44])
+		}
+
+		if binary.LittleEndian.Uint32(data[24:28]) != 8000 || binary.LittleEndian.Uint16(data[34:36]) != 16 {
+			t.Errorf("Expected 8000Hz 16 bit audio, got %v", data[20:36])
+		}
+
+		// Three bars of 4/4 at 120 bpm is 6 seconds
+		expected := 6 * 8000 * 2
+		if int(binary.LittleEndian.Uint32(data[40:44])) != expected || len(data) != 44+expected {
+			t.Errorf("Expected %d bytes of %s audio, got %d", expected, voice, len(data)-44)
+		}
+
+		samples := make([]int16, (len(data)-44)/2)
+		_ = binary.Read(bytes.NewReader(data[44:]), binary.LittleEndian, samples)
+		loudest := func(from int, to int) int {
+			res := 0
+			for _, sample := range samples[from:to] {
+				res = max(res, int(math.Abs(float64(sample))))
+			}
+			return res
+		}
+
+		if loudest(0, 16000) < 10000 || loudest(16000, 32000) < 10000 {
+			t.Errorf("Expected the %s chords to be heard", voice)
+		}
+
+		if loudest(32000, 48000) != 0 {
+			t.Errorf("Expected silence for N.C. with the %s voice", voice)
+		}
+	}
+}
+
+// magnitude returns how strongly the samples contain the frequency
+func magnitude(samples []float64, frequency float64, sampleRate int) float64 {
+	re, im := 0.0, 0.0
+	for index, sample := range samples {
+		angle := 2 * math.Pi * frequency * float64(index) / float64(sampleRate)
+		re += sample * math.Cos(angle)
+		im += sample * math.Sin(angle)
+	}
+
+	return math.Hypot(re, im) / float64(len(samples))
+}
+
+func TestWAVSinePitch(t *testing.T) {
+	content := parse(t, "A5\n")
+	content.Transpose(2)
+	samples := synthesize(content, WAVOptions{Voice: VoiceSine, SampleRate: 8000, Tempo: 60})
+	steady := samples[8000:16000]
+
+	// B5 sounds B and F#, over a B in the bass
+	for _, frequency := range []float64{123.47, 246.94, 369.99} {
+		if magnitude(steady, frequency, 8000) < 0.05 {
+			t.Errorf("Expected to hear %.2fHz", frequency)
+		}
+	}
+
+	for _, frequency := range []float64{110, 220, 329.63, 261.63} {
+		if magnitude(steady, frequency, 8000) > 0.01 {
+			t.Errorf("Expected not to hear %.2fHz", frequency)
+		}
+	}
+}
+
+func TestWAVUnknownVoice(t *testing.T) {
+	content := parse(t, "C\n")
+	err := WriteWAV(&bytes.Buffer{}, content, WAVOptions{Voice: "kazoo"})
+	if err == nil {
+		t.Errorf("Expected an error for an unknown voice")
+	}
+}
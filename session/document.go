@@ -0,0 +1,544 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"wails-lead-sheet/formats"
+	"wails-lead-sheet/parser"
+)
+
+var ErrNothingToUndo = errors.New("nothing to undo")
+var ErrNothingToRedo = errors.New("nothing to redo")
+
+// Document is an open song. It keeps the song's text, the transposition,
+// NNS and simplification state, and the history of changes, and renders
+// the parsed content from those whenever something changes.
+type Document struct {
+	mu        sync.Mutex
+	id        string
+	path      string
+	lines     []string
+	transpose int
+	nnsKey    string
+	// nnsMinorDash writes minor chords as 6- rather than 6m in Nashville numbers
+	nnsMinorDash bool
+	history      *history
+	metadata     parser.Metadata
+	content      parser.ParsedContent
+
+	overrides      []parser.Override
+	savedOverrides []parser.Override
+	keepsOverrides bool
+
+	tabWidth int
+	imported formats.DecodeReport
+
+	simplification parser.Simplification
+	capo           int
+}
+
+// Open reads and parses the song at the given path, along with the line
+// types the user has set for it
+func Open(path string) (*Document, error) {
+	return OpenWithTabWidth(path, formats.DefaultTabWidth)
+}
+
+// OpenWithTabWidth reads and parses the song at the given path, expanding
+// any tabs in it to stops the given number of columns apart
+func OpenWithTabWidth(path string, tabWidth int) (*Document, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := readOverrides(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := newDocument(path, contents, tabWidth)
+	if err != nil {
+		return nil, err
+	}
+
+	d.overrides, d.savedOverrides, d.keepsOverrides = overrides, overrides, true
+	if len(overrides) > 0 {
+		err = d.render()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// New parses the given song text into a document. The path's extension
+// decides which format the text is read as.
+func New(path string, text string) (*Document, error) {
+	return newDocument(path, []byte(text), formats.DefaultTabWidth)
+}
+
+func newDocument(path string, data []byte, tabWidth int) (*Document, error) {
+	content, report, err := importFile(path, data, tabWidth)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Document{path: path, history: newHistory(DefaultHistoryLimit), metadata: content.Metadata, content: content}
+	d.lines = sourceLines(content)
+	d.tabWidth, d.imported = tabWidth, report
+
+	return d, nil
+}
+
+// importFile decodes a song file's text and parses it
+func importFile(path string, data []byte, tabWidth int) (parser.ParsedContent, formats.DecodeReport, error) {
+	text, report, err := formats.Decode(data, tabWidth)
+	if err != nil {
+		return parser.ParsedContent{}, report, err
+	}
+
+	content, err := formats.Import(path, []byte(text))
+
+	return content, report, err
+}
+
+// sourceLines returns the text of each line as it needs to be kept to parse
+// the same way again, which includes any import markup
+func sourceLines(content parser.ParsedContent) []string {
+	res := make([]string, len(content.Lines))
+	for index, line := range content.Lines {
+		if line.Markup != "" {
+			res[index] = line.Markup
+		} else {
+			res[index] = line.Text
+		}
+	}
+
+	return res
+}
+
+// ID returns the ID the document was given when it was added to a Manager
+func (d *Document) ID() string {
+	return d.id
+}
+
+// Path returns the file the document was read from
+func (d *Document) Path() string {
+	return d.path
+}
+
+// Content returns the current rendering of the document
+func (d *Document) Content() parser.ParsedContent {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.content
+}
+
+// Text returns the document as it is currently shown, one line per line
+func (d *Document) Text() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	res := ""
+	for _, line := range d.content.Lines {
+		res += line.String() + "\n"
+	}
+
+	return res
+}
+
+// ImportReport says how the song's file was decoded when it was last read
+func (d *Document) ImportReport() formats.DecodeReport {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.imported
+}
+
+// Diagnostics returns the warnings about lines which may have been misread
+func (d *Document) Diagnostics() []parser.Diagnostic {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.content.Diagnostics
+}
+
+// Transposition returns the number of half steps the document is transposed by
+func (d *Document) Transposition() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.transpose
+}
+
+// NNSKey returns the key used for Nashville numbers, or "" if they're not shown
+func (d *Document) NNSKey() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.nnsKey
+}
+
+// Simplification returns how the document's chords are simplified
+func (d *Document) Simplification() parser.Simplification {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.simplification
+}
+
+// Capo returns the fret the capo goes on for the simplified chords, or 0 for none
+func (d *Document) Capo() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.capo
+}
+
+// CanUndo reports whether there is a change to undo
+func (d *Document) CanUndo() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return len(d.history.done) > 0
+}
+
+// CanRedo reports whether there is an undone change to redo
+func (d *Document) CanRedo() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return len(d.history.undone) > 0
+}
+
+// Transpose moves the document's chords up (or down, for negative steps) by half steps
+func (d *Document) Transpose(steps int) error {
+	if steps == 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.run(transposeCommand{steps: steps})
+}
+
+// SwitchToNNS shows Nashville numbers relative to the given key, with minor
+// chords written with a dash, as 6-, if asked
+func (d *Document) SwitchToNNS(key string, minorDash bool) error {
+	if len(key) == 0 || key[0] < 'A' || key[0] > 'G' {
+		return fmt.Errorf("%#v is not a key", key)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.run(nnsCommand{key: key, minorDash: minorDash, previous: d.nnsKey, previousMinorDash: d.nnsMinorDash})
+}
+
+// ClearNNS goes back to showing chords instead of Nashville numbers
+func (d *Document) ClearNNS() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.nnsKey == "" {
+		return nil
+	}
+
+	return d.run(nnsCommand{key: "", minorDash: d.nnsMinorDash, previous: d.nnsKey, previousMinorDash: d.nnsMinorDash})
+}
+
+// Simplify shows the document's chords simplified as the options say. The
+// song itself is left as it is, and the empty options show it unsimplified.
+func (d *Document) Simplify(options parser.Simplification) error {
+	err := options.Validate()
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if options == d.simplification {
+		return nil
+	}
+
+	return d.run(simplifyCommand{options: options, previous: d.simplification})
+}
+
+// RenameSection changes the name of the section header on the given line
+func (d *Document) RenameSection(lineNumber int, name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	line, err := d.line(lineNumber)
+	if err != nil {
+		return err
+	}
+
+	if line.Type != parser.LineTypes.SECTION {
+		return fmt.Errorf("line %d is not a section header", lineNumber)
+	}
+
+	name = strings.TrimSpace(name)
+	if strings.ContainsAny(name, "[]\n") {
+		return fmt.Errorf("%#v is not a valid section name", name)
+	}
+
+	indent := line.Text[:len(line.Text)-len(strings.TrimLeft(line.Text, " \t"))]
+
+	return d.editLine(lineNumber, indent+"["+name+"]")
+}
+
+// EditChord replaces one chord on a chord line. The chord is given as it
+// is currently shown, and is stored in the song's original key.
+func (d *Document) EditChord(lineNumber int, partIndex int, chord string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	line, err := d.line(lineNumber)
+	if err != nil {
+		return err
+	}
+
+	if line.Type != parser.LineTypes.CHORDS {
+		return fmt.Errorf("line %d is not a chord line", lineNumber)
+	}
+
+	if partIndex < 0 || partIndex >= len(line.Parts) || line.Parts[partIndex].Type != parser.LetterRunTypes.CHORDRUN {
+		return fmt.Errorf("part %d of line %d is not a chord", partIndex, lineNumber)
+	}
+
+	newChord := parser.MakeChord(strings.TrimSpace(chord))
+	if newChord.Note == "" {
+		return fmt.Errorf("%#v is not a chord", chord)
+	}
+
+	newChord.Transpose(-d.transpose)
+
+	// The line is read as chords on its own, as it was among the others
+	source := parser.ParsedContent{Overrides: []parser.Override{parser.MakeOverride(line.Text, parser.LineTypes.CHORDS)}, TabWidth: d.tabWidth}
+	err = source.ParseContent(d.lines[lineNumber])
+	if err != nil {
+		return err
+	}
+
+	parts := source.Lines[0].Parts
+	parser.ReplaceChord(parts, partIndex, newChord.String())
+
+	return d.editLine(lineNumber, parser.ChordLineText(parts, line.Markup != ""))
+}
+
+// reharmonize works out the changes the reharmonization makes to the lines
+// from first to last, in the song's original key
+func (d *Document) reharmonize(technique string, first int, last int) (parser.ParsedContent, []parser.ChordChange, error) {
+	content, err := d.parse()
+	if err != nil {
+		return content, nil, err
+	}
+
+	changes, err := content.ReharmonizeLines(technique, d.metadata.Key, first, last)
+
+	return content, changes, err
+}
+
+// PreviewReharmonization returns the chords the reharmonization would change
+// on the lines from first to last, as they are currently shown, without
+// changing anything
+func (d *Document) PreviewReharmonization(technique string, first int, last int) ([]parser.ChordChange, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, changes, err := d.reharmonize(technique, first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	shown := func(text string) string {
+		chord := parser.MakeChord(text)
+		if chord.Note == "" {
+			return text
+		}
+
+		chord.Transpose(d.transpose)
+		return chord.String()
+	}
+
+	for index := range changes {
+		changes[index].From, changes[index].To = shown(changes[index].From), shown(changes[index].To)
+	}
+
+	return changes, nil
+}
+
+// Reharmonize rewrites the chords on the lines from first to last with the
+// given reharmonization, as one change which can be undone
+func (d *Document) Reharmonize(technique string, first int, last int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	content, changes, err := d.reharmonize(technique, first, last)
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+
+	texts, err := content.ApplyChordChanges(changes)
+	if err != nil {
+		return err
+	}
+
+	previous := make(map[int]string)
+	for lineNumber := range texts {
+		previous[lineNumber] = d.lines[lineNumber]
+	}
+
+	return d.run(editLinesCommand{texts: texts, previous: previous})
+}
+
+// Reload replaces the document's text, keeping its transposition and NNS
+// state. The change history is cleared, since it refers to the old lines.
+// If the new text can't be parsed the document is left as it was.
+func (d *Document) Reload(text string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	content, report, err := importFile(d.path, []byte(text), d.tabWidth)
+	if err != nil {
+		return err
+	}
+
+	previous, previousMetadata := d.lines, d.metadata
+	d.lines = sourceLines(content)
+	d.metadata = content.Metadata
+
+	err = d.render()
+	if err != nil {
+		d.lines, d.metadata = previous, previousMetadata
+		return err
+	}
+
+	d.imported = report
+	d.history.clear()
+
+	return nil
+}
+
+// Undo reverts the most recent change
+func (d *Document) Undo() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	cmd, found := d.history.popDone()
+	if !found {
+		return ErrNothingToUndo
+	}
+
+	cmd.revert(d)
+
+	err := d.render()
+	if err != nil {
+		return err
+	}
+
+	return d.saveOverrides()
+}
+
+// Redo re-applies the most recently undone change
+func (d *Document) Redo() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	cmd, found := d.history.popUndone()
+	if !found {
+		return ErrNothingToRedo
+	}
+
+	err := cmd.apply(d)
+	if err != nil {
+		return err
+	}
+
+	err = d.render()
+	if err != nil {
+		return err
+	}
+
+	return d.saveOverrides()
+}
+
+func (d *Document) editLine(lineNumber int, text string) error {
+	previous := d.lines[lineNumber]
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("line %d can't be made empty", lineNumber)
+	}
+
+	if text == previous {
+		return nil
+	}
+
+	return d.run(editLineCommand{lineNumber: lineNumber, text: text, previous: previous})
+}
+
+func (d *Document) run(cmd command) error {
+	err := cmd.apply(d)
+	if err != nil {
+		return err
+	}
+
+	err = d.render()
+	if err != nil {
+		cmd.revert(d)
+		return err
+	}
+
+	d.history.push(cmd)
+
+	return d.saveOverrides()
+}
+
+func (d *Document) line(lineNumber int) (parser.Line, error) {
+	if lineNumber < 0 || lineNumber >= len(d.content.Lines) {
+		return parser.Line{}, fmt.Errorf("there is no line %d", lineNumber)
+	}
+
+	return d.content.Lines[lineNumber], nil
+}
+
+// parse parses the document's lines as they were written, before they are
+// transposed or shown as numbers
+func (d *Document) parse() (parser.ParsedContent, error) {
+	content := parser.ParsedContent{Overrides: d.overrides, TabWidth: d.tabWidth}
+	err := content.ParseContent(strings.Join(d.lines, "\n"))
+
+	return content, err
+}
+
+func (d *Document) render() error {
+	content, err := d.parse()
+	if err != nil {
+		return err
+	}
+
+	content.Transpose(d.transpose)
+	content.Metadata = d.metadata.Transposed(d.transpose)
+	// Nashville numbers are worked out from the chords as written, so the
+	// simplified chords aren't shown with them
+	d.capo = 0
+	if d.simplification != (parser.Simplification{}) && d.nnsKey == "" {
+		d.capo = content.Simplify(d.simplification)
+	}
+
+	if d.nnsKey != "" {
+		content.SwitchToNNS(d.nnsKey, d.nnsMinorDash)
+	}
+
+	d.content = content
+
+	return nil
+}
@@ -0,0 +1,332 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+
+	"wails-lead-sheet/parser"
+)
+
+const song = `[Verse]
+C       G       Am
+These are the lyrics
+`
+
+func renderedLines(d *Document) []string {
+	content := d.Content()
+	res := make([]string, len(content.Lines))
+	for index, line := range content.Lines {
+		res[index] = line.String()
+	}
+
+	return res
+}
+
+func verifyLines(t *testing.T, d *Document, expected []string) {
+	t.Helper()
+	got := renderedLines(d)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, got)
+	}
+}
+
+func TestTransposeUndoRedo(t *testing.T) {
+	d, err := New("song.txt", song)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.Transpose(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transposed := []string{"[Verse]", "D       A       Bm", "These are the lyrics"}
+	verifyLines(t, d, transposed)
+
+	err = d.Undo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifyLines(t, d, []string{"[Verse]", "C       G       Am", "These are the lyrics"})
+
+	err = d.Redo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifyLines(t, d, transposed)
+
+	if d.CanRedo() {
+		t.Errorf("Expected nothing left to redo")
+	}
+}
+
+func TestUndoWithNothingToUndo(t *testing.T) {
+	d, err := New("song.txt", song)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Undo() != ErrNothingToUndo {
+		t.Errorf("Expected ErrNothingToUndo")
+	}
+
+	if d.Redo() != ErrNothingToRedo {
+		t.Errorf("Expected ErrNothingToRedo")
+	}
+}
+
+func TestNewCommandClearsRedo(t *testing.T) {
+	d, err := New("song.txt", song)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = d.Transpose(1)
+	_ = d.Undo()
+	_ = d.SwitchToNNS("C", false)
+
+	if d.CanRedo() {
+		t.Errorf("Expected a new command to clear the redo list")
+	}
+
+	verifyLines(t, d, []string{"[Verse]", "1       5       6m", "These are the lyrics"})
+
+	_ = d.ClearNNS()
+	verifyLines(t, d, []string{"[Verse]", "C       G       Am", "These are the lyrics"})
+
+	_ = d.SwitchToNNS("C", true)
+	verifyLines(t, d, []string{"[Verse]", "1       5       6-", "These are the lyrics"})
+
+	_ = d.Undo()
+	_ = d.Undo()
+	verifyLines(t, d, []string{"[Verse]", "1       5       6m", "These are the lyrics"})
+}
+
+func TestHistoryIsBounded(t *testing.T) {
+	d, err := New("song.txt", song)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.history = newHistory(3)
+	for range 5 {
+		_ = d.Transpose(1)
+	}
+
+	undone := 0
+	for d.Undo() == nil {
+		undone += 1
+	}
+
+	if undone != 3 {
+		t.Errorf("Expected 3 undos, got %d", undone)
+	}
+
+	if d.Transposition() != 2 {
+		t.Errorf("Expected transposition 2 after undoing, got %d", d.Transposition())
+	}
+}
+
+func TestRenameSection(t *testing.T) {
+	d, err := New("song.txt", song)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.RenameSection(0, "Chorus")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifyLines(t, d, []string{"[Chorus]", "C       G       Am", "These are the lyrics"})
+
+	if d.RenameSection(1, "Chorus") == nil {
+		t.Errorf("Expected renaming a chord line to fail")
+	}
+
+	_ = d.Undo()
+	verifyLines(t, d, []string{"[Verse]", "C       G       Am", "These are the lyrics"})
+}
+
+func TestEditChordKeepsColumns(t *testing.T) {
+	d, err := New("song.txt", song)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.EditChord(1, 2, "Gmaj7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifyLines(t, d, []string{"[Verse]", "C       Gmaj7   Am", "These are the lyrics"})
+
+	if d.EditChord(1, 1, "bogus") == nil {
+		t.Errorf("Expected editing to a non-chord to fail")
+	}
+}
+
+func TestEditChordWhileTransposed(t *testing.T) {
+	d, err := New("song.txt", song)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = d.Transpose(2)
+	err = d.EditChord(1, 0, "E")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifyLines(t, d, []string{"[Verse]", "E       A       Bm", "These are the lyrics"})
+
+	_ = d.Transpose(-2)
+	verifyLines(t, d, []string{"[Verse]", "D       G       Am", "These are the lyrics"})
+}
+
+func TestEditChordKeepsUltimateGuitarMarkup(t *testing.T) {
+	d, err := New("song.txt", "[Verse]\n[ch]Cadd11[/ch]   [ch]G[/ch]\nSome words here\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.EditChord(1, 2, "D")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifyLines(t, d, []string{"[Verse]", "Cadd11   D", "Some words here"})
+
+	_ = d.Transpose(2)
+	verifyLines(t, d, []string{"[Verse]", "Dadd11   E", "Some words here"})
+}
+
+func TestOnSongDocumentKeepsMetadata(t *testing.T) {
+	d, err := New("song.onsong", "Song\nKey: C\n\nVerse:\n[C]These are the [G]lyrics\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifyLines(t, d, []string{"[Verse]", "C             G", "These are the lyrics"})
+
+	err = d.Transpose(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifyLines(t, d, []string{"[Verse]", "D             A", "These are the lyrics"})
+	if d.Content().Metadata.Key != "D" {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", "D", d.Content().Metadata.Key)
+	}
+
+	err = d.EditChord(1, 2, "B")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifyLines(t, d, []string{"[Verse]", "D             B", "These are the lyrics"})
+	if d.Content().Metadata.Title != "Song" {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", "Song", d.Content().Metadata.Title)
+	}
+}
+
+func TestDiagnosticsFollowEdits(t *testing.T) {
+	d, err := New("song.txt", "[Verse]\n[ch]Riff[/ch]   [ch]G[/ch]\nThese are the lyrics\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(d.Diagnostics()) != 1 || d.Diagnostics()[0].Kind != parser.DiagnosticEmptyChord {
+		t.Fatalf("Expected a warning about Riff, got %#v", d.Diagnostics())
+	}
+
+	err = d.EditChord(1, 0, "E")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(d.Diagnostics()) != 0 {
+		t.Errorf("Expected no warnings once the chord is fixed, got %#v", d.Diagnostics())
+	}
+
+	verifyLines(t, d, []string{"[Verse]", "E      G", "These are the lyrics"})
+}
+
+func TestEditChordOnLineReadAsChordsByItsNeighbours(t *testing.T) {
+	d, err := New("song.txt", "[Verse]\nC  day  way\nThe words go here\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.EditChord(1, 0, "D")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifyLines(t, d, []string{"[Verse]", "D  day  way", "The words go here"})
+}
+
+func TestReharmonizePreviewThenApply(t *testing.T) {
+	d, err := New("song.txt", song)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = d.Transpose(2)
+	preview, err := d.PreviewReharmonization(parser.BassWalkDown, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []parser.ChordChange{{LineNumber: 1, Part: 2, From: "A", To: "A/C#"}}
+	if !reflect.DeepEqual(preview, expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, preview)
+	}
+
+	verifyLines(t, d, []string{"[Verse]", "D       A       Bm", "These are the lyrics"})
+
+	err = d.Reharmonize(parser.BassWalkDown, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifyLines(t, d, []string{"[Verse]", "D       A/C#    Bm", "These are the lyrics"})
+
+	_ = d.Undo()
+	verifyLines(t, d, []string{"[Verse]", "D       A       Bm", "These are the lyrics"})
+}
+
+func TestSimplifyIsAViewWhichCanBeUndone(t *testing.T) {
+	d, err := New("song.txt", "[Verse]\nBbmaj7/D  F7sus4  Gm9\nThese are the lyrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.Simplify(parser.Simplification{Level: parser.TriadLevel, DropBass: true, Capo: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifyLines(t, d, []string{"[Verse]", "G         Dsus4   Em", "These are the lyrics"})
+	if d.Capo() != 3 || d.Content().Metadata.Capo != "3" {
+		t.Errorf("Expected capo 3, got %d and %#v", d.Capo(), d.Content().Metadata.Capo)
+	}
+
+	if d.lines[1] != "Bbmaj7/D  F7sus4  Gm9" {
+		t.Errorf("Expected the song's chords to be kept, got %#v", d.lines[1])
+	}
+
+	_ = d.Undo()
+	verifyLines(t, d, []string{"[Verse]", "Bbmaj7/D  F7sus4  Gm9", "These are the lyrics"})
+	if d.Capo() != 0 {
+		t.Errorf("Expected no capo, got %d", d.Capo())
+	}
+
+	err = d.Simplify(parser.Simplification{Level: "ninths"})
+	if err == nil {
+		t.Errorf("Expected an error simplifying to an unknown level")
+	}
+}
@@ -0,0 +1,141 @@
+package session
+
+import (
+	"wails-lead-sheet/parser"
+)
+
+// DefaultHistoryLimit is the number of commands a document remembers for undo
+const DefaultHistoryLimit = 100
+
+type command interface {
+	apply(d *Document) error
+	revert(d *Document)
+}
+
+type history struct {
+	limit  int
+	done   []command
+	undone []command
+}
+
+func newHistory(limit int) *history {
+	if limit < 1 {
+		limit = DefaultHistoryLimit
+	}
+
+	return &history{limit: limit}
+}
+
+func (h *history) push(cmd command) {
+	h.done = append(h.done, cmd)
+	if len(h.done) > h.limit {
+		h.done = h.done[len(h.done)-h.limit:]
+	}
+
+	h.undone = nil
+}
+
+func (h *history) popDone() (command, bool) {
+	if len(h.done) == 0 {
+		return nil, false
+	}
+
+	cmd := h.done[len(h.done)-1]
+	h.done = h.done[:len(h.done)-1]
+	h.undone = append(h.undone, cmd)
+
+	return cmd, true
+}
+
+func (h *history) popUndone() (command, bool) {
+	if len(h.undone) == 0 {
+		return nil, false
+	}
+
+	cmd := h.undone[len(h.undone)-1]
+	h.undone = h.undone[:len(h.undone)-1]
+	h.done = append(h.done, cmd)
+
+	return cmd, true
+}
+
+func (h *history) clear() {
+	h.done = nil
+	h.undone = nil
+}
+
+type transposeCommand struct {
+	steps int
+}
+
+func (c transposeCommand) apply(d *Document) error {
+	d.transpose += c.steps
+	return nil
+}
+
+func (c transposeCommand) revert(d *Document) {
+	d.transpose -= c.steps
+}
+
+type nnsCommand struct {
+	key               string
+	minorDash         bool
+	previous          string
+	previousMinorDash bool
+}
+
+func (c nnsCommand) apply(d *Document) error {
+	d.nnsKey, d.nnsMinorDash = c.key, c.minorDash
+	return nil
+}
+
+func (c nnsCommand) revert(d *Document) {
+	d.nnsKey, d.nnsMinorDash = c.previous, c.previousMinorDash
+}
+
+type editLineCommand struct {
+	lineNumber int
+	text       string
+	previous   string
+}
+
+func (c editLineCommand) apply(d *Document) error {
+	d.lines[c.lineNumber] = c.text
+	return nil
+}
+
+func (c editLineCommand) revert(d *Document) {
+	d.lines[c.lineNumber] = c.previous
+}
+
+type editLinesCommand struct {
+	texts    map[int]string
+	previous map[int]string
+}
+
+func (c editLinesCommand) apply(d *Document) error {
+	for lineNumber, text := range c.texts {
+		d.lines[lineNumber] = text
+	}
+	return nil
+}
+
+func (c editLinesCommand) revert(d *Document) {
+	for lineNumber, text := range c.previous {
+		d.lines[lineNumber] = text
+	}
+}
+
+type simplifyCommand struct {
+	options  parser.Simplification
+	previous parser.Simplification
+}
+
+func (c simplifyCommand) apply(d *Document) error {
+	d.simplification = c.options
+	return nil
+}
+
+func (c simplifyCommand) revert(d *Document) {
+	d.simplification = c.previous
+}
@@ -0,0 +1,103 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"wails-lead-sheet/formats"
+)
+
+// Manager keeps track of the open documents, by ID
+type Manager struct {
+	mu        sync.Mutex
+	next      int
+	documents map[string]*Document
+	tabWidth  int
+}
+
+func NewManager() *Manager {
+	return &Manager{documents: make(map[string]*Document), tabWidth: formats.DefaultTabWidth}
+}
+
+// SetTabWidth sets how far apart tab stops are in songs opened from now on
+func (m *Manager) SetTabWidth(tabWidth int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tabWidth = tabWidth
+}
+
+// Open reads the song at the given path and returns the new document's ID
+func (m *Manager) Open(path string) (string, error) {
+	m.mu.Lock()
+	tabWidth := m.tabWidth
+	m.mu.Unlock()
+
+	document, err := OpenWithTabWidth(path, tabWidth)
+	if err != nil {
+		return "", err
+	}
+
+	return m.Add(document), nil
+}
+
+// Add starts tracking the given document and returns its ID
+func (m *Manager) Add(document *Document) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.next += 1
+	id := strconv.Itoa(m.next)
+	document.id = id
+	m.documents[id] = document
+
+	return id
+}
+
+// Get returns the document with the given ID
+func (m *Manager) Get(id string) (*Document, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	document, found := m.documents[id]
+	if !found {
+		return nil, fmt.Errorf("there is no open document %#v", id)
+	}
+
+	return document, nil
+}
+
+// Close stops tracking the document with the given ID
+func (m *Manager) Close(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, found := m.documents[id]; !found {
+		return fmt.Errorf("there is no open document %#v", id)
+	}
+
+	delete(m.documents, id)
+
+	return nil
+}
+
+// IDs returns the IDs of the open documents, oldest first
+func (m *Manager) IDs() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := make([]string, 0, len(m.documents))
+	for id := range m.documents {
+		res = append(res, id)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		left, _ := strconv.Atoi(res[i])
+		right, _ := strconv.Atoi(res[j])
+		return left < right
+	})
+
+	return res
+}
@@ -0,0 +1,90 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManagerKeepsDocumentsApart(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	_ = os.WriteFile(first, []byte(song), 0o644)
+	_ = os.WriteFile(second, []byte("[Chorus]\nD  A\nOther words\n"), 0o644)
+
+	manager := NewManager()
+	firstID, err := manager.Open(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secondID, err := manager.Open(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if firstID == secondID {
+		t.Fatalf("Expected different IDs, got %#v twice", firstID)
+	}
+
+	document, err := manager.Get(firstID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = document.Transpose(1)
+	other, _ := manager.Get(secondID)
+	if other.Transposition() != 0 {
+		t.Errorf("Expected transposing one document to leave the other alone")
+	}
+
+	if !reflect.DeepEqual(manager.IDs(), []string{firstID, secondID}) {
+		t.Errorf("Expected IDs %#v, got %#v", []string{firstID, secondID}, manager.IDs())
+	}
+
+	err = manager.Close(firstID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = manager.Get(firstID); err == nil {
+		t.Errorf("Expected a closed document to be gone")
+	}
+
+	if manager.Close(firstID) == nil {
+		t.Errorf("Expected closing twice to fail")
+	}
+}
+
+func TestManagerOpenMissingFile(t *testing.T) {
+	manager := NewManager()
+	_, err := manager.Open(filepath.Join(t.TempDir(), "missing.txt"))
+	if err == nil {
+		t.Errorf("Expected an error opening a missing file")
+	}
+}
+
+func TestManagerOpensWithTabWidth(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tabbed.txt")
+	_ = os.WriteFile(path, []byte("\xef\xbb\xbf[Verse]\r\nG\tC\r\nSing\tit\r\n"), 0o644)
+
+	manager := NewManager()
+	manager.SetTabWidth(4)
+	id, err := manager.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document, _ := manager.Get(id)
+	expected := "[Verse]\nG   C\nSing    it\n"
+	if document.Text() != expected {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, document.Text())
+	}
+
+	report := document.ImportReport()
+	if !report.BOM || report.LineEndings != "CRLF" || report.TabsExpanded != 2 {
+		t.Errorf("Expected the BOM, line endings and tabs to be reported, got %#v", report)
+	}
+}
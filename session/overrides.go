@@ -0,0 +1,143 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"wails-lead-sheet/parser"
+)
+
+// Line type overrides are kept next to the song, in a file named after it,
+// so the song itself is left as it was written
+const overridesSuffix = ".lines.json"
+
+// OverridesPath returns the file the line type overrides for the song at
+// the given path are kept in
+func OverridesPath(path string) string {
+	return path + overridesSuffix
+}
+
+// readOverrides reads the overrides kept for the song at the given path. A
+// song with no overrides file has none.
+func readOverrides(path string) ([]parser.Override, error) {
+	contents, err := os.ReadFile(OverridesPath(path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	overrides := make([]parser.Override, 0)
+	err = json.Unmarshal(contents, &overrides)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", OverridesPath(path), err)
+	}
+
+	return overrides, nil
+}
+
+// writeOverrides keeps the overrides for the song at the given path, or
+// removes the file when there are none
+func writeOverrides(path string, overrides []parser.Override) error {
+	if len(overrides) == 0 {
+		err := os.Remove(OverridesPath(path))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	contents, err := json.MarshalIndent(overrides, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(OverridesPath(path), append(contents, '\n'), 0o644)
+}
+
+type overrideCommand struct {
+	overrides []parser.Override
+	previous  []parser.Override
+}
+
+func (c overrideCommand) apply(d *Document) error {
+	d.overrides = c.overrides
+	return nil
+}
+
+func (c overrideCommand) revert(d *Document) {
+	d.overrides = c.previous
+}
+
+// Overrides returns the line types the user has set
+func (d *Document) Overrides() []parser.Override {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return slices.Clone(d.overrides)
+}
+
+// SetLineType makes the given line, and any others which say the same
+// thing, the given type, whatever the parser makes of them
+func (d *Document) SetLineType(lineNumber int, lineType parser.LineType) error {
+	if lineType != parser.LineTypes.TEXT && lineType != parser.LineTypes.SECTION &&
+		lineType != parser.LineTypes.CHORDS && lineType != parser.LineTypes.LYRICS {
+		return fmt.Errorf("%#v is not a type a line can be given", lineType.String())
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	line, err := d.line(lineNumber)
+	if err != nil {
+		return err
+	}
+
+	if line.Type == parser.LineTypes.EMPTY {
+		return fmt.Errorf("line %d is empty", lineNumber)
+	}
+
+	overrides := parser.WithOverride(d.overrides, parser.MakeOverride(line.Text, lineType))
+
+	return d.run(overrideCommand{overrides: overrides, previous: d.overrides})
+}
+
+// ClearLineType lets the parser decide the type of the given line again
+func (d *Document) ClearLineType(lineNumber int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	line, err := d.line(lineNumber)
+	if err != nil {
+		return err
+	}
+
+	if !line.Overridden {
+		return nil
+	}
+
+	return d.run(overrideCommand{overrides: parser.WithoutOverride(d.overrides, line.Text), previous: d.overrides})
+}
+
+// saveOverrides keeps the overrides next to the song, if it was opened
+// from a file and they have changed since they were last kept
+func (d *Document) saveOverrides() error {
+	if !d.keepsOverrides || slices.Equal(d.overrides, d.savedOverrides) {
+		return nil
+	}
+
+	err := writeOverrides(d.path, d.overrides)
+	if err != nil {
+		return err
+	}
+
+	d.savedOverrides = d.overrides
+
+	return nil
+}
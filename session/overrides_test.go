@@ -0,0 +1,96 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"wails-lead-sheet/parser"
+)
+
+func TestLineTypeOverrideSurvivesEdits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "song.txt")
+	_ = os.WriteFile(path, []byte("[Verse]\nA B C D E F G\n"), 0o644)
+
+	d, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Content().Lines[1].Type != parser.LineTypes.CHORDS {
+		t.Fatalf("Expected the alphabet to be misread as chords")
+	}
+
+	err = d.SetLineType(1, parser.LineTypes.LYRICS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Content().Lines[1].Type != parser.LineTypes.LYRICS {
+		t.Errorf("Expected the line to be lyrics once overridden")
+	}
+
+	if _, err := os.Stat(OverridesPath(path)); err != nil {
+		t.Fatalf("Expected the override to be kept next to the song: %v", err)
+	}
+
+	err = d.Reload("[Intro]\nC  G\n\n[Verse]\nC       G\nA B C D E F G\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Content().Lines[5].Type != parser.LineTypes.LYRICS {
+		t.Errorf("Expected the override to follow the line when lines are added above it")
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reopened.Content().Lines[1].Type != parser.LineTypes.LYRICS {
+		t.Errorf("Expected the override to be read back when the song is opened again")
+	}
+
+	err = d.ClearLineType(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Content().Lines[5].Type != parser.LineTypes.CHORDS {
+		t.Errorf("Expected the parser to decide the line's type again")
+	}
+
+	if _, err := os.Stat(OverridesPath(path)); !os.IsNotExist(err) {
+		t.Errorf("Expected the overrides file to be removed when there are none")
+	}
+
+	_ = d.Undo()
+	if d.Content().Lines[5].Type != parser.LineTypes.LYRICS {
+		t.Errorf("Expected undo to bring the override back")
+	}
+}
+
+func TestSetLineTypeChecks(t *testing.T) {
+	d, err := New("song.txt", "[Verse]\nC  G\n\nWords\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.SetLineType(2, parser.LineTypes.LYRICS) == nil {
+		t.Errorf("Expected an empty line not to be given a type")
+	}
+
+	if d.SetLineType(1, parser.LineTypes.EMPTY) == nil {
+		t.Errorf("Expected a line not to be made empty")
+	}
+
+	err = d.SetLineType(3, parser.LineTypes.SECTION)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Content().Lines[3].Type != parser.LineTypes.SECTION {
+		t.Errorf("Expected the line to be a section")
+	}
+}
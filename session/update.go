@@ -0,0 +1,78 @@
+package session
+
+import (
+	"wails-lead-sheet/parser"
+)
+
+// RenderedLine is one line of a document as it is currently shown
+type RenderedLine struct {
+	LineNumber int
+	Type       parser.LineType
+	Text       string
+	Parts      []parser.LetterRun
+	Confidence float64
+	Overridden bool
+}
+
+// Update describes how a document changed: the lines which are different,
+// the number of lines it now has, and its current state
+type Update struct {
+	ID             string
+	LineCount      int
+	Lines          []RenderedLine
+	Transposition  int
+	NNSKey         string
+	Simplification parser.Simplification
+	Capo           int
+	CanUndo        bool
+	CanRedo        bool
+}
+
+func renderLine(line parser.Line) RenderedLine {
+	return RenderedLine{LineNumber: line.LineNumber, Type: line.Type, Text: line.String(), Parts: line.Parts, Confidence: line.Confidence, Overridden: line.Overridden}
+}
+
+func sameLine(before parser.Line, after parser.Line) bool {
+	return before.Type == after.Type && before.String() == after.String() && before.Confidence == after.Confidence &&
+		before.Overridden == after.Overridden
+}
+
+// Render returns every line of the document
+func (d *Document) Render() Update {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.updateSince(parser.ParsedContent{})
+}
+
+// UpdateSince returns the lines which differ from the given earlier content
+func (d *Document) UpdateSince(before parser.ParsedContent) Update {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.updateSince(before)
+}
+
+func (d *Document) updateSince(before parser.ParsedContent) Update {
+	res := Update{
+		ID:             d.id,
+		LineCount:      len(d.content.Lines),
+		Lines:          make([]RenderedLine, 0),
+		Transposition:  d.transpose,
+		NNSKey:         d.nnsKey,
+		Simplification: d.simplification,
+		Capo:           d.capo,
+		CanUndo:        len(d.history.done) > 0,
+		CanRedo:        len(d.history.undone) > 0,
+	}
+
+	for index, line := range d.content.Lines {
+		if index < len(before.Lines) && sameLine(before.Lines[index], line) {
+			continue
+		}
+
+		res.Lines = append(res.Lines, renderLine(line))
+	}
+
+	return res
+}
@@ -0,0 +1,44 @@
+package session
+
+import (
+	"testing"
+)
+
+func TestRenderReturnsEveryLine(t *testing.T) {
+	d, err := New("song.txt", song)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	update := d.Render()
+	if update.LineCount != 3 || len(update.Lines) != 3 {
+		t.Errorf("Expected 3 lines, got count %d with %d lines", update.LineCount, len(update.Lines))
+	}
+
+	if update.CanUndo || update.CanRedo {
+		t.Errorf("Expected nothing to undo or redo on a new document")
+	}
+}
+
+func TestUpdateSinceOnlyHasChangedLines(t *testing.T) {
+	d, err := New("song.txt", song)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := d.Content()
+	_ = d.Transpose(-1)
+	update := d.UpdateSince(before)
+
+	if len(update.Lines) != 1 {
+		t.Fatalf("Expected 1 changed line, got %#v", update.Lines)
+	}
+
+	if update.Lines[0].LineNumber != 1 || update.Lines[0].Text != "B       Gb      Abm" {
+		t.Errorf("Expected the transposed chord line, got %#v", update.Lines[0])
+	}
+
+	if update.Transposition != -1 || !update.CanUndo {
+		t.Errorf("Expected transposition -1 with undo available, got %#v", update)
+	}
+}
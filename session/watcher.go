@@ -0,0 +1,115 @@
+package session
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+const DefaultPollInterval = 500 * time.Millisecond
+const DefaultDebounce = 300 * time.Millisecond
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher checks the files of the open documents for changes, and reloads
+// a document once its file has stopped changing for the debounce time
+type Watcher struct {
+	manager      *Manager
+	PollInterval time.Duration
+	Debounce     time.Duration
+	OnReload     func(update Update)
+	OnError      func(id string, err error)
+
+	seen    map[string]fileState
+	pending map[string]time.Time
+}
+
+func NewWatcher(manager *Manager, onReload func(update Update), onError func(id string, err error)) *Watcher {
+	return &Watcher{
+		manager:      manager,
+		PollInterval: DefaultPollInterval,
+		Debounce:     DefaultDebounce,
+		OnReload:     onReload,
+		OnError:      onError,
+		seen:         make(map[string]fileState),
+		pending:      make(map[string]time.Time),
+	}
+}
+
+// Run checks for changes until the context is done
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.poll(now)
+		}
+	}
+}
+
+func (w *Watcher) poll(now time.Time) {
+	open := make(map[string]bool)
+	for _, id := range w.manager.IDs() {
+		open[id] = true
+		document, err := w.manager.Get(id)
+		if err != nil {
+			continue
+		}
+
+		info, err := os.Stat(document.Path())
+		if err != nil {
+			continue
+		}
+
+		state := fileState{modTime: info.ModTime(), size: info.Size()}
+		previous, found := w.seen[id]
+		w.seen[id] = state
+		if !found {
+			continue
+		}
+
+		if state != previous {
+			w.pending[id] = now
+			continue
+		}
+
+		changed, isPending := w.pending[id]
+		if isPending && now.Sub(changed) >= w.Debounce {
+			delete(w.pending, id)
+			w.reload(document)
+		}
+	}
+
+	for id := range w.seen {
+		if !open[id] {
+			delete(w.seen, id)
+			delete(w.pending, id)
+		}
+	}
+}
+
+func (w *Watcher) reload(document *Document) {
+	contents, err := os.ReadFile(document.Path())
+	if err == nil {
+		before := document.Content()
+		err = document.Reload(string(contents))
+		if err == nil {
+			if w.OnReload != nil {
+				w.OnReload(document.UpdateSince(before))
+			}
+
+			return
+		}
+	}
+
+	if w.OnError != nil {
+		w.OnError(document.ID(), err)
+	}
+}
@@ -0,0 +1,92 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeSong(t *testing.T, path string, text string, modTime time.Time) {
+	t.Helper()
+	err := os.WriteFile(path, []byte(text), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Chtimes(path, modTime, modTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcherReloadsAfterDebounce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "song.txt")
+	start := time.Now()
+	writeSong(t, path, song, start)
+
+	manager := NewManager()
+	id, err := manager.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document, _ := manager.Get(id)
+	_ = document.Transpose(2)
+
+	updates := make([]Update, 0)
+	watcher := NewWatcher(manager, func(update Update) {
+		updates = append(updates, update)
+	}, func(id string, err error) {
+		t.Errorf("Unexpected reload error for %s: %s", id, err)
+	})
+
+	watcher.poll(start)
+	writeSong(t, path, "[Verse]\nC       F       Am\nThese are new lyrics\n", start.Add(time.Second))
+	watcher.poll(start.Add(10 * time.Millisecond))
+	writeSong(t, path, "[Verse]\nC       F       G\nThese are new lyrics\n", start.Add(2*time.Second))
+	watcher.poll(start.Add(20 * time.Millisecond))
+	watcher.poll(start.Add(30 * time.Millisecond))
+
+	if len(updates) != 0 {
+		t.Fatalf("Expected no reload while the file is still changing, got %d", len(updates))
+	}
+
+	watcher.poll(start.Add(20*time.Millisecond + watcher.Debounce))
+	if len(updates) != 1 {
+		t.Fatalf("Expected one reload, got %d", len(updates))
+	}
+
+	verifyLines(t, document, []string{"[Verse]", "D       G       A", "These are new lyrics"})
+	if updates[0].ID != id || len(updates[0].Lines) != 2 {
+		t.Errorf("Expected an update for %s with 2 changed lines, got %#v", id, updates[0])
+	}
+}
+
+func TestWatcherKeepsLastGoodContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "song.txt")
+	start := time.Now()
+	writeSong(t, path, song, start)
+
+	manager := NewManager()
+	id, _ := manager.Open(path)
+	document, _ := manager.Get(id)
+
+	failures := 0
+	watcher := NewWatcher(manager, func(update Update) {
+		t.Errorf("Expected no reload, got %#v", update)
+	}, func(failedID string, err error) {
+		failures += 1
+	})
+
+	watcher.poll(start)
+	writeSong(t, path, "\n\n", start.Add(time.Second))
+	watcher.poll(start.Add(10 * time.Millisecond))
+	watcher.poll(start.Add(10*time.Millisecond + watcher.Debounce))
+
+	if failures != 1 {
+		t.Errorf("Expected one reload failure, got %d", failures)
+	}
+
+	verifyLines(t, document, []string{"[Verse]", "C       G       Am", "These are the lyrics"})
+}
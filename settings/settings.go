@@ -0,0 +1,289 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const appDirectory = "wails-lead-sheet"
+const fileName = "settings.json"
+
+const (
+	SpellingAuto   = "auto"
+	SpellingSharps = "sharps"
+	SpellingFlats  = "flats"
+
+	NNSMinorDash   = "dash"
+	NNSMinorSuffix = "m"
+
+	PageSizeLetter = "letter"
+	PageSizeA4     = "a4"
+)
+
+// Preferences are the user-visible settings which are kept between runs
+type Preferences struct {
+	LibraryRoot    string `json:"libraryRoot"`
+	LastDirectory  string `json:"lastDirectory"`
+	SpellingPolicy string `json:"spellingPolicy"`
+	NNSMinorStyle  string `json:"nnsMinorStyle"`
+	PageSize       string `json:"pageSize"`
+	ColumnCount    int    `json:"columnCount"`
+	FontSize       int    `json:"fontSize"`
+	Instrument     string `json:"instrument"`
+	TabWidth       int    `json:"tabWidth"`
+}
+
+// Store holds the preferences along with the file they are saved in.
+// Keys found in the file which this version does not know about are
+// kept, and written back out on save. A file which can't be read at all
+// is copied aside before it is first saved over.
+type Store struct {
+	mu     sync.Mutex
+	path   string
+	prefs  Preferences
+	extra  map[string]json.RawMessage
+	backup bool
+}
+
+// Defaults returns the preferences used when no settings file exists
+func Defaults() Preferences {
+	return Preferences{
+		SpellingPolicy: SpellingAuto,
+		NNSMinorStyle:  NNSMinorDash,
+		PageSize:       PageSizeLetter,
+		ColumnCount:    2,
+		FontSize:       10,
+		Instrument:     "guitar",
+		TabWidth:       8,
+	}
+}
+
+// DefaultPath returns the settings file location under the user's config directory
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, appDirectory, fileName), nil
+}
+
+// Load reads the settings at the given path. A missing file is not an
+// error, it just gives the defaults.
+func Load(path string) (*Store, error) {
+	store := &Store{path: path, prefs: Defaults(), extra: make(map[string]json.RawMessage)}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return store, nil
+		}
+
+		return store, err
+	}
+
+	raw := make(map[string]json.RawMessage)
+	err = json.Unmarshal(contents, &raw)
+	if err != nil {
+		store.backup = true
+		return store, fmt.Errorf("unable to read settings file %s: %w", path, err)
+	}
+
+	known, err := knownKeys()
+	if err != nil {
+		return store, err
+	}
+
+	// Each known key is read on its own, so one which can't be read, as when
+	// another version stores it differently, is left at its default
+	failed := make([]string, 0)
+	for key, value := range raw {
+		if !known[key] {
+			store.extra[key] = value
+			continue
+		}
+
+		err = json.Unmarshal([]byte(fmt.Sprintf("{%q: %s}", key, value)), &store.prefs)
+		if err != nil {
+			failed = append(failed, key)
+		}
+	}
+
+	if store.prefs.validate() != nil {
+		store.prefs.fillInvalid(Defaults())
+	}
+
+	if len(failed) > 0 {
+		slices.Sort(failed)
+		return store, fmt.Errorf("unable to read %s from settings file %s", strings.Join(failed, ", "), path)
+	}
+
+	return store, nil
+}
+
+// Path returns the file the settings are saved in
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Get returns a copy of the current preferences
+func (s *Store) Get() Preferences {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.prefs
+}
+
+// Update changes the preferences with the given function, and saves them
+// if the result is valid. Invalid changes are discarded.
+func (s *Store) Update(change func(*Preferences)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	updated := s.prefs
+	change(&updated)
+	err := updated.validate()
+	if err != nil {
+		return err
+	}
+
+	s.prefs = updated
+
+	return s.save()
+}
+
+func (s *Store) save() error {
+	contents, err := json.Marshal(s.prefs)
+	if err != nil {
+		return err
+	}
+
+	merged := make(map[string]json.RawMessage)
+	for key, value := range s.extra {
+		merged[key] = value
+	}
+
+	err = json.Unmarshal(contents, &merged)
+	if err != nil {
+		return err
+	}
+
+	contents, err = json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	if s.backup {
+		err = os.Rename(s.path, s.path+".bak")
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		s.backup = false
+	}
+
+	tempFile := s.path + ".tmp"
+	err = os.WriteFile(tempFile, contents, 0o644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile, s.path)
+}
+
+func knownKeys() (map[string]bool, error) {
+	contents, err := json.Marshal(Preferences{})
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make(map[string]json.RawMessage)
+	err = json.Unmarshal(contents, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]bool)
+	for key := range raw {
+		res[key] = true
+	}
+
+	return res, nil
+}
+
+func validSpellingPolicy(policy string) bool {
+	return policy == SpellingAuto || policy == SpellingSharps || policy == SpellingFlats
+}
+
+func validNNSMinorStyle(style string) bool {
+	return style == NNSMinorDash || style == NNSMinorSuffix
+}
+
+func validPageSize(size string) bool {
+	return size == PageSizeLetter || size == PageSizeA4
+}
+
+func (p Preferences) validate() error {
+	if !validSpellingPolicy(p.SpellingPolicy) {
+		return fmt.Errorf("unknown spelling policy %#v", p.SpellingPolicy)
+	}
+
+	if !validNNSMinorStyle(p.NNSMinorStyle) {
+		return fmt.Errorf("unknown NNS minor style %#v", p.NNSMinorStyle)
+	}
+
+	if !validPageSize(p.PageSize) {
+		return fmt.Errorf("unknown page size %#v", p.PageSize)
+	}
+
+	if p.ColumnCount < 1 || p.ColumnCount > 2 {
+		return fmt.Errorf("column count must be 1 or 2, not %d", p.ColumnCount)
+	}
+
+	if p.FontSize < 6 || p.FontSize > 24 {
+		return fmt.Errorf("font size must be between 6 and 24, not %d", p.FontSize)
+	}
+
+	if p.TabWidth < 1 || p.TabWidth > 16 {
+		return fmt.Errorf("tab width must be between 1 and 16, not %d", p.TabWidth)
+	}
+
+	return nil
+}
+
+func (p *Preferences) fillInvalid(defaults Preferences) {
+	if !validSpellingPolicy(p.SpellingPolicy) {
+		p.SpellingPolicy = defaults.SpellingPolicy
+	}
+
+	if !validNNSMinorStyle(p.NNSMinorStyle) {
+		p.NNSMinorStyle = defaults.NNSMinorStyle
+	}
+
+	if !validPageSize(p.PageSize) {
+		p.PageSize = defaults.PageSize
+	}
+
+	if p.ColumnCount < 1 || p.ColumnCount > 2 {
+		p.ColumnCount = defaults.ColumnCount
+	}
+
+	if p.FontSize < 6 || p.FontSize > 24 {
+		p.FontSize = defaults.FontSize
+	}
+
+	if p.TabWidth < 1 || p.TabWidth > 16 {
+		p.TabWidth = defaults.TabWidth
+	}
+}
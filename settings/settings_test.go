@@ -0,0 +1,193 @@
+package settings
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadMissingFileGivesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "settings.json")
+	store, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error loading missing file: %s", err)
+	}
+
+	if !reflect.DeepEqual(store.Get(), Defaults()) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", Defaults(), store.Get())
+	}
+}
+
+func TestUpdateSavesAndReloads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lead-sheet", "settings.json")
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.Update(func(p *Preferences) {
+		p.LibraryRoot = "/songs"
+		p.LastDirectory = "/songs/rock"
+		p.SpellingPolicy = SpellingFlats
+		p.ColumnCount = 1
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error updating settings: %s", err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(reloaded.Get(), store.Get()) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", store.Get(), reloaded.Get())
+	}
+}
+
+func TestUnknownKeysSurviveSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	err := os.WriteFile(path, []byte(`{"libraryRoot": "/songs", "futureOption": {"on": true}}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if store.Get().LibraryRoot != "/songs" {
+		t.Errorf("Expected library root /songs, got %#v", store.Get().LibraryRoot)
+	}
+
+	err = store.Update(func(p *Preferences) {
+		p.FontSize = 12
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw := make(map[string]any)
+	err = json.Unmarshal(contents, &raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(raw["futureOption"], map[string]any{"on": true}) {
+		t.Errorf("Expected unknown key to survive, got %#v", raw["futureOption"])
+	}
+
+	if raw["fontSize"] != 12.0 {
+		t.Errorf("Expected fontSize 12, got %#v", raw["fontSize"])
+	}
+}
+
+func TestInvalidUpdateIsDiscarded(t *testing.T) {
+	store, err := Load(filepath.Join(t.TempDir(), "settings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.Update(func(p *Preferences) {
+		p.SpellingPolicy = "mixed"
+	})
+	if err == nil {
+		t.Errorf("Expected an error for an unknown spelling policy")
+	}
+
+	if store.Get().SpellingPolicy != SpellingAuto {
+		t.Errorf("Expected spelling policy to be unchanged, got %#v", store.Get().SpellingPolicy)
+	}
+}
+
+func TestInvalidValuesInFileFallBackToDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	err := os.WriteFile(path, []byte(`{"pageSize": "tabloid", "columnCount": 7, "instrument": "ukulele"}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefs := store.Get()
+	if prefs.PageSize != PageSizeLetter || prefs.ColumnCount != 2 || prefs.Instrument != "ukulele" {
+		t.Errorf("Expected invalid values replaced by defaults, got %#v", prefs)
+	}
+}
+
+func TestUnreadableKeyKeepsTheRest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	err := os.WriteFile(path, []byte(`{"libraryRoot": "/songs", "fontSize": "large", "futureOption": 3}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := Load(path)
+	if err == nil {
+		t.Errorf("Expected an error reading fontSize")
+	}
+
+	expected := Defaults()
+	expected.LibraryRoot = "/songs"
+	if !reflect.DeepEqual(store.Get(), expected) {
+		t.Errorf("Expected:\n'%#v'\ngot:\n'%#v'", expected, store.Get())
+	}
+
+	err = store.Update(func(p *Preferences) { p.ColumnCount = 1 })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := make(map[string]any)
+	err = json.Unmarshal(contents, &saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if saved["futureOption"] != 3.0 || saved["libraryRoot"] != "/songs" {
+		t.Errorf("Expected the other keys to be kept, got:\n'%#v'", saved)
+	}
+}
+
+func TestUnreadableFileIsBackedUpBeforeSaving(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	err := os.WriteFile(path, []byte(`{"libraryRoot": "/songs",`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := Load(path)
+	if err == nil {
+		t.Errorf("Expected an error reading a broken file")
+	}
+
+	err = store.Update(func(p *Preferences) { p.ColumnCount = 1 })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := os.ReadFile(path + ".bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(backup) != `{"libraryRoot": "/songs",` {
+		t.Errorf("Expected the broken file to be backed up, got %#v", string(backup))
+	}
+}